@@ -0,0 +1,243 @@
+package amocrm_v4
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type AccountWithType string
+
+const (
+	// AccountWithAmojoID Добавляет в ответ ID аккаунта в сервисе чатов
+	AccountWithAmojoID AccountWithType = "amojo_id"
+
+	// AccountWithAmojoRights Добавляет в ответ информацию о доступности функционала создания групповых и использования директ чатов пользователями
+	AccountWithAmojoRights AccountWithType = "amojo_rights"
+
+	// AccountWithUsersGroups Добавляет в ответ информацию о доступных группах пользователей аккаунта
+	AccountWithUsersGroups AccountWithType = "users_groups"
+
+	// AccountWithTaskTypes Добавляет в ответ информацию о доступных типах задач в аккаунте
+	AccountWithTaskTypes AccountWithType = "task_types"
+
+	// AccountWithVersion Добавляет в ответ информацию о текущей версии amoCRM
+	AccountWithVersion AccountWithType = "version"
+
+	// AccountWithEntityNames Добавляет в ответ названия сущностей с их переводами и формами чисел
+	AccountWithEntityNames AccountWithType = "entity_names"
+
+	// AccountWithDatetimeSettings Добавляет в ответ информацию о текущих настройках форматов даты и времени аккаунта
+	AccountWithDatetimeSettings AccountWithType = "datetime_settings"
+
+	// AccountWithDriveUrl Добавляет в ответ адрес сервиса файлов
+	AccountWithDriveUrl AccountWithType = "drive_url"
+
+	// AccountWithIsApiFilterEnabled Добавляет в ответ информацию о доступности фильтрации через API
+	AccountWithIsApiFilterEnabled AccountWithType = "is_api_filter_enabled"
+)
+
+type GetAccountQueryParams struct {
+	With []AccountWithType `url:"with,comma,omitempty"`
+}
+
+type account struct {
+	Id                      int         `json:"id"`                         //ID аккаунта
+	Name                    string      `json:"name"`                       //Название аккаунта
+	Subdomain               string      `json:"subdomain"`                  //Субдомен аккаунта
+	CreatedAt               int         `json:"created_at"`                 //Дата создания аккаунта в Unix Timestamp
+	CreatedBy               int         `json:"created_by"`                 //ID пользователя, создавшего аккаунт
+	UpdatedAt               int         `json:"updated_at"`                 //Дата последнего изменения свойства аккаунта в Unix Timestamp
+	UpdatedBy               int         `json:"updated_by"`                 //ID пользователя, который последним менял свойства аккаунта
+	CurrentUserId           int         `json:"current_user_id"`            //ID текущего пользователя
+	Country                 string      `json:"country"`                    //Страна, указанная в настройках аккаунта
+	Currency                string      `json:"currency"`                   //Валюта аккаунта (используемая при работе с бюджетом сделок)
+	CurrencySymbol          string      `json:"currency_symbol"`            //Символ валюты аккаунта
+	CustomersMode           string      `json:"customers_mode"`             //Режим покупателей. Возможные варианты: unavailable, disabled, segments, dynamic, periodicity
+	IsUnsortedOn            bool        `json:"is_unsorted_on"`             //Включен ли функционал "Неразобранного" в аккаунте
+	IsLossReasonEnabled     bool        `json:"is_loss_reason_enabled"`     //Включен ли функционал причин отказа
+	IsHelpbotEnabled        bool        `json:"is_helpbot_enabled"`         //Включен ли функционал Типовых вопросов
+	IsTechnicalAccount      bool        `json:"is_technical_account"`       //Является ли данный аккаунт техническим
+	ContactNameDisplayOrder int         `json:"contact_name_display_order"` //Порядок отображения имен контактов (1 – Имя, Фамилия; 2 – Фамилия, Имя)
+	AmojoId                 string      `json:"amojo_id,omitempty"`         //Требуется GET параметр with. Уникальный идентификатор аккаунта для работы с сервисом чатов amoJo
+	Uuid                    string      `json:"uuid,omitempty"`             //Требуется GET параметр with. Уникальный идентификатор аккаунта
+	Version                 int         `json:"version,omitempty"`          //Требуется GET параметр with. Версия amoCRM
+	DriveUrl                string      `json:"drive_url,omitempty"`        //Требуется GET параметр with. Адрес сервиса файлов
+	IsApiFilterEnabled      bool        `json:"is_api_filter_enabled"`      //Требуется GET параметр with. Доступна ли фильтрация через API
+	EntityNames             interface{} `json:"entity_names,omitempty"`     //Требуется GET параметр with. Названия сущностей с их переводами
+	Links                   links       `json:"_links"`
+	Embedded                struct {
+		AmojoRights struct {
+			CanDirect       bool `json:"can_direct"`        //Доступны ли директ чаты пользователям
+			CanCreateGroups bool `json:"can_create_groups"` //Доступно ли создание групповых чатов пользователям
+		} `json:"amojo_rights"`
+		UsersGroups []struct {
+			Id   int    `json:"id"`
+			Name string `json:"name"`
+			Uuid string `json:"uuid,omitempty"`
+		} `json:"users_groups"`
+		TaskTypes []struct {
+			Id     TaskTypeIdType `json:"id"`
+			Name   string         `json:"name"`
+			Color  interface{}    `json:"color"`
+			IconId interface{}    `json:"icon_id"`
+			Code   string         `json:"code"`
+		} `json:"task_types"`
+		DatetimeSettings struct {
+			DatePattern      string `json:"date_pattern"`
+			ShortDatePattern string `json:"short_date_pattern"`
+			ShortTimePattern string `json:"short_time_pattern"`
+			DateFormat       string `json:"date_format"`
+			TimeFormat       string `json:"time_format"`
+			Timezone         string `json:"timezone"`
+			TimezoneOffset   string `json:"timezone_offset"`
+		} `json:"datetime_settings"`
+	} `json:"_embedded"`
+
+	with []AccountWithType
+}
+
+// accountCache хранит последний полученный ответ /api/v4/account,
+// чтобы остальные части клиента могли использовать параметры аккаунта без лишних запросов
+type accountCache struct {
+	sync.RWMutex
+	acc *account
+}
+
+// Account Метод позволяет получить параметры текущего аккаунта.
+// Результат кешируется: повторный вызов с тем же или меньшим набором with не выполняет запрос.
+// Метод также можно использовать для проверки авторизации после NewClient.
+func (a *Amo) Account(with ...AccountWithType) (*account, error) {
+	if acc := a.api().cachedAccount(); acc != nil && acc.loaded(with...) {
+		return acc, nil
+	}
+
+	return a.RefreshAccount(with...)
+}
+
+// RefreshAccount Метод запрашивает параметры аккаунта без учета кеша и обновляет кеш.
+// Вместе с with повторно запрашиваются данные, уже загруженные в кеш, чтобы они не пропали из него.
+func (a *Amo) RefreshAccount(with ...AccountWithType) (*account, error) {
+	ret := account{}
+
+	api := a.api()
+	if acc := api.cachedAccount(); acc != nil {
+		with = mergeAccountWith(acc.with, with)
+	}
+
+	err := api.request(requestOpts{
+		Method:        http.MethodGet,
+		Path:          "/api/v4/account",
+		URLParameters: &GetAccountQueryParams{With: with},
+		Ret:           &ret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret.with = with
+
+	api.account.Lock()
+	api.account.acc = &ret
+	api.account.Unlock()
+
+	return &ret, nil
+}
+
+func (a *authSettings) cachedAccount() *account {
+	a.account.RLock()
+	defer a.account.RUnlock()
+
+	return a.account.acc
+}
+
+// mergeAccountWith Объединяет наборы with без повторов
+func mergeAccountWith(loaded, with []AccountWithType) []AccountWithType {
+	ret := append([]AccountWithType{}, loaded...)
+	for _, w := range with {
+		found := false
+		for _, l := range ret {
+			if l == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ret = append(ret, w)
+		}
+	}
+
+	return ret
+}
+
+// loaded Проверяет, были ли запрошены указанные with при получении аккаунта
+func (acc *account) loaded(with ...AccountWithType) bool {
+	for _, w := range with {
+		found := false
+		for _, l := range acc.with {
+			if l == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Location Возвращает часовой пояс аккаунта. Требуется with=datetime_settings,
+// иначе возвращается UTC.
+func (acc *account) Location() *time.Location {
+	tz := acc.Embedded.DatetimeSettings.Timezone
+	if tz == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// Time Переводит Unix Timestamp из ответа API во время в часовом поясе аккаунта
+func (acc *account) Time(timestamp int) time.Time {
+	return time.Unix(int64(timestamp), 0).In(acc.Location())
+}
+
+// HasTaskType Проверяет, существует ли тип задачи в аккаунте. Требуется with=task_types.
+func (acc *account) HasTaskType(id TaskTypeIdType) bool {
+	if id == TaskCall || id == TaskMeeting {
+		return true
+	}
+
+	for _, t := range acc.Embedded.TaskTypes {
+		if t.Id == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateTaskTypes Проверяет типы задач по закешированным данным аккаунта.
+// Если аккаунт не запрашивался с with=task_types, проверка не выполняется.
+func validateTaskTypes(api *authSettings, tsk Tasks) error {
+	acc := api.cachedAccount()
+	if acc == nil || !acc.loaded(AccountWithTaskTypes) {
+		return nil
+	}
+
+	for _, t := range tsk {
+		if t.TaskTypeId != 0 && !acc.HasTaskType(t.TaskTypeId) {
+			return fmt.Errorf("тип задачи %d не найден в аккаунте %s", t.TaskTypeId, acc.Subdomain)
+		}
+	}
+
+	return nil
+}
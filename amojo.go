@@ -0,0 +1,322 @@
+package amocrm_v4
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	AmojoAmoCRMEndpoint = "https://amojo.amocrm.ru" // Адрес API чатов amoCRM
+	AmojoKommoEndpoint  = "https://amojo.kommo.com" // Адрес API чатов Kommo
+)
+
+type AmojoMessageType string
+type AmojoDeliveryStatus int
+
+const (
+	AmojoTextMessage     AmojoMessageType = "text"
+	AmojoPictureMessage  AmojoMessageType = "picture"
+	AmojoVideoMessage    AmojoMessageType = "video"
+	AmojoFileMessage     AmojoMessageType = "file"
+	AmojoVoiceMessage    AmojoMessageType = "voice"
+	AmojoAudioMessage    AmojoMessageType = "audio"
+	AmojoStickerMessage  AmojoMessageType = "sticker"
+	AmojoLocationMessage AmojoMessageType = "location"
+	AmojoContactMessage  AmojoMessageType = "contact"
+)
+
+const (
+	AmojoDelivered     AmojoDeliveryStatus = 1  // Сообщение доставлено
+	AmojoRead          AmojoDeliveryStatus = 2  // Сообщение прочитано
+	AmojoDeliveryError AmojoDeliveryStatus = -1 // Сообщение не доставлено
+)
+
+// Amojo Клиент API чатов для канала интеграции. Запросы подписываются секретом канала.
+type Amojo struct {
+	channelID     string
+	channelSecret string
+	api           *authSettings
+}
+
+// AmojoChannel Канал, подключенный к аккаунту
+type AmojoChannel struct {
+	AccountId      string `json:"account_id"` // amojo_id аккаунта
+	ScopeId        string `json:"scope_id"`   // ID, по которому отправляются запросы чатов аккаунта
+	Title          string `json:"title"`
+	HookApiVersion string `json:"hook_api_version"`
+}
+
+type AmojoSource struct {
+	ExternalId string `json:"external_id"`
+}
+
+type AmojoProfile struct {
+	Phone string `json:"phone,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// AmojoUser Участник чата. Для сообщений менеджеров заполняется RefId – ID пользователя в amojo.
+type AmojoUser struct {
+	Id          string        `json:"id,omitempty"`
+	RefId       string        `json:"ref_id,omitempty"`
+	Name        string        `json:"name,omitempty"`
+	Avatar      string        `json:"avatar,omitempty"`
+	Profile     *AmojoProfile `json:"profile,omitempty"`
+	ProfileLink string        `json:"profile_link,omitempty"`
+}
+
+// AmojoChat Чат для создания в аккаунте
+type AmojoChat struct {
+	ConversationId string       `json:"conversation_id"` // ID чата во внешней системе
+	Source         *AmojoSource `json:"source,omitempty"`
+	User           AmojoUser    `json:"user"`
+	AccountId      string       `json:"account_id,omitempty"`
+}
+
+// AmojoCreatedChat Созданный чат
+type AmojoCreatedChat struct {
+	Id   string `json:"id"`
+	User struct {
+		Id       string `json:"id"`
+		ClientId string `json:"client_id"`
+		Name     string `json:"name"`
+		Avatar   string `json:"avatar"`
+		Phone    string `json:"phone"`
+		Email    string `json:"email"`
+	} `json:"user"`
+}
+
+type AmojoLocation struct {
+	Lon float64 `json:"lon"`
+	Lat float64 `json:"lat"`
+}
+
+type AmojoContact struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone"`
+}
+
+// AmojoMessageContent Содержимое сообщения
+type AmojoMessageContent struct {
+	Type     AmojoMessageType `json:"type"`
+	Text     string           `json:"text,omitempty"`
+	Media    string           `json:"media,omitempty"` // Ссылка на файл для picture, video, file, voice, audio и sticker
+	FileName string           `json:"file_name,omitempty"`
+	FileSize int              `json:"file_size,omitempty"`
+	Location *AmojoLocation   `json:"location,omitempty"`
+	Contact  *AmojoContact    `json:"contact,omitempty"`
+}
+
+// AmojoMessage Сообщение для отправки в чат
+type AmojoMessage struct {
+	Timestamp         int64               `json:"timestamp"`
+	MsecTimestamp     int64               `json:"msec_timestamp"`
+	MsgId             string              `json:"msgid"` // ID сообщения во внешней системе
+	ConversationId    string              `json:"conversation_id"`
+	ConversationRefId string              `json:"conversation_ref_id,omitempty"` // ID чата в amojo
+	Sender            AmojoUser           `json:"sender"`
+	Receiver          *AmojoUser          `json:"receiver,omitempty"`
+	Message           AmojoMessageContent `json:"message"`
+	Silent            bool                `json:"silent"`
+	Source            *AmojoSource        `json:"source,omitempty"`
+}
+
+// AmojoSentMessage Результат отправки сообщения
+type AmojoSentMessage struct {
+	ConversationId string `json:"conversation_id"`
+	SenderId       string `json:"sender_id"`
+	ReceiverId     string `json:"receiver_id"`
+	MsgId          string `json:"msgid"`
+	RefId          string `json:"ref_id"` // ID сообщения в amojo
+}
+
+type amojoConnectRequest struct {
+	AccountId      string `json:"account_id"`
+	Title          string `json:"title,omitempty"`
+	HookApiVersion string `json:"hook_api_version,omitempty"`
+}
+
+type amojoEvent struct {
+	EventType string        `json:"event_type"`
+	Payload   *AmojoMessage `json:"payload"`
+}
+
+// NewAmojoClient Создает клиент API чатов для канала channelID с секретом channelSecret
+func NewAmojoClient(channelID, channelSecret string) *Amojo {
+	return &Amojo{
+		channelID:     channelID,
+		channelSecret: channelSecret,
+		api: &authSettings{
+			client:   http.Client{},
+			endpoint: AmojoAmoCRMEndpoint,
+		},
+	}
+}
+
+// WithEndpoint Задает адрес API чатов, например AmojoKommoEndpoint для аккаунтов Kommo
+func (a *Amojo) WithEndpoint(endpoint string) *Amojo {
+	a.api.endpoint = strings.TrimSuffix(endpoint, "/")
+
+	return a
+}
+
+// Connect Подключает канал к аккаунту. amojoAccountId – amojo_id аккаунта (см. AccountWithAmojoID).
+// Возвращенный ScopeId используется во всех остальных запросах.
+func (a *Amojo) Connect(amojoAccountId, title string) (*AmojoChannel, error) {
+	ret := AmojoChannel{}
+
+	err := a.request(http.MethodPost, fmt.Sprintf("/v2/origin/custom/%s/connect", a.channelID), &amojoConnectRequest{
+		AccountId:      amojoAccountId,
+		Title:          title,
+		HookApiVersion: "v2",
+	}, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// Disconnect Отключает канал от аккаунта
+func (a *Amojo) Disconnect(amojoAccountId string) error {
+	return a.request(http.MethodDelete, fmt.Sprintf("/v2/origin/custom/%s/disconnect", a.channelID), &amojoConnectRequest{
+		AccountId: amojoAccountId,
+	}, nil)
+}
+
+// CreateChat Создает чат в аккаунте
+func (a *Amojo) CreateChat(scopeId string, chat *AmojoChat) (*AmojoCreatedChat, error) {
+	ret := AmojoCreatedChat{}
+
+	err := a.request(http.MethodPost, fmt.Sprintf("/v2/origin/custom/%s/chats", scopeId), chat, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// SendMessage Отправляет сообщение в чат. Если время сообщения не указано, используется текущее.
+// Переданное сообщение не изменяется.
+func (a *Amojo) SendMessage(scopeId string, msg *AmojoMessage) (*AmojoSentMessage, error) {
+	return a.sendMessage(scopeId, *msg)
+}
+
+// ImportMessage Импортирует сообщение из истории переписки без уведомлений в аккаунте.
+// Переданное сообщение не изменяется.
+func (a *Amojo) ImportMessage(scopeId string, msg *AmojoMessage) (*AmojoSentMessage, error) {
+	m := *msg
+	m.Silent = true
+
+	return a.sendMessage(scopeId, m)
+}
+
+// sendMessage Отправляет копию сообщения, дополняя ее временем отправки
+func (a *Amojo) sendMessage(scopeId string, msg AmojoMessage) (*AmojoSentMessage, error) {
+	if msg.MsgId == "" {
+		return nil, errors.New("не указан msgid сообщения")
+	}
+	if msg.ConversationId == "" && msg.ConversationRefId == "" {
+		return nil, fmt.Errorf("не указан чат сообщения %s", msg.MsgId)
+	}
+
+	if msg.Timestamp == 0 && msg.MsecTimestamp == 0 {
+		now := time.Now()
+		msg.Timestamp = now.Unix()
+		msg.MsecTimestamp = now.UnixNano() / int64(time.Millisecond)
+	} else if msg.MsecTimestamp == 0 {
+		msg.MsecTimestamp = msg.Timestamp * 1000
+	} else if msg.Timestamp == 0 {
+		msg.Timestamp = msg.MsecTimestamp / 1000
+	}
+
+	ret := struct {
+		NewMessage AmojoSentMessage `json:"new_message"`
+	}{}
+
+	err := a.request(http.MethodPost, fmt.Sprintf("/v2/origin/custom/%s", scopeId), &amojoEvent{
+		EventType: "new_message",
+		Payload:   &msg,
+	}, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ret.NewMessage, nil
+}
+
+// DeliveryStatus Обновляет статус доставки сообщения msgId. errText указывается для AmojoDeliveryError.
+func (a *Amojo) DeliveryStatus(scopeId, msgId string, status AmojoDeliveryStatus, errText string) error {
+	req := struct {
+		MsgId          string              `json:"msgid"`
+		DeliveryStatus AmojoDeliveryStatus `json:"delivery_status"`
+		ErrorCode      int                 `json:"error_code,omitempty"`
+		Error          string              `json:"error,omitempty"`
+	}{
+		MsgId:          msgId,
+		DeliveryStatus: status,
+		Error:          errText,
+	}
+	if status == AmojoDeliveryError {
+		// 905 – код ошибки доставки с текстом, который отображается в аккаунте
+		req.ErrorCode = 905
+	}
+
+	return a.request(http.MethodPost, fmt.Sprintf("/v2/origin/custom/%s/%s/delivery_status", scopeId, msgId), &req, nil)
+}
+
+// Typing Показывает в чате, что собеседник печатает
+func (a *Amojo) Typing(scopeId, conversationId, senderId string) error {
+	req := struct {
+		ConversationId string    `json:"conversation_id"`
+		Sender         AmojoUser `json:"sender"`
+	}{
+		ConversationId: conversationId,
+		Sender:         AmojoUser{Id: senderId},
+	}
+
+	return a.request(http.MethodPost, fmt.Sprintf("/v2/origin/custom/%s/typing", scopeId), &req, nil)
+}
+
+func (a *Amojo) request(method, path string, data interface{}, ret interface{}) error {
+	return a.api.request(requestOpts{
+		Method:         method,
+		Path:           path,
+		DataParameters: data,
+		Ret:            ret,
+		Sign:           a.sign,
+	})
+}
+
+// sign Подписывает запрос: Content-MD5 от тела и X-Signature – HMAC-SHA1 секретом канала
+// от метода, Content-MD5, Content-Type, Date и пути запроса, разделенных переводом строки
+func (a *Amojo) sign(req *http.Request, body []byte) {
+	sum := md5.Sum(body)
+	contentMD5 := hex.EncodeToString(sum[:])
+	date := time.Now().UTC().Format(time.RFC1123Z)
+
+	req.Header.Set("Date", date)
+	req.Header.Set("Content-MD5", contentMD5)
+	req.Header.Set("X-Signature", amojoSignature(a.channelSecret, strings.Join([]string{
+		req.Method,
+		contentMD5,
+		req.Header.Get("Content-Type"),
+		date,
+		req.URL.Path,
+	}, "\n")))
+}
+
+// amojoSignature Возвращает HMAC-SHA1 от data в hex
+func amojoSignature(secret, data string) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(data))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,85 @@
+package amocrm_v4
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testAmojoSecret = "5a44c5dff55f3c15a4cce8d7c4b81f3a"
+
+func TestAmojoSignature(t *testing.T) {
+	tests := []struct {
+		secret string
+		data   string
+		want   string
+	}{
+		{
+			secret: "key",
+			data:   "The quick brown fox jumps over the lazy dog",
+			want:   "de7c9b85b8b78aa6bc8a7a36f70a90701c9db4d9",
+		},
+		{
+			secret: testAmojoSecret,
+			data: strings.Join([]string{
+				"POST",
+				"3a3e0ef8a1f4ff8e2a1c5b5d2f8a1a5c",
+				"application/json",
+				"Thu, 01 Jan 2015 00:00:00 +0000",
+				"/v2/origin/custom/f90ba33d-c9d9-44da-b76c-c349b0ecbe41_52e591f7-c98f-4255-8495-827210138c81",
+			}, "\n"),
+			want: "3408670386905a7a1b7c59b7a47e9cce71404e56",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := amojoSignature(tt.secret, tt.data); got != tt.want {
+			t.Errorf("amojoSignature(%q) = %s, want %s", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestAmojoSign(t *testing.T) {
+	var (
+		header http.Header
+		path   string
+		body   []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		path = r.URL.Path
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	a := NewAmojoClient("channel", testAmojoSecret).WithEndpoint(srv.URL)
+	if err := a.Typing("scope", "chat", "user"); err != nil {
+		t.Fatal(err)
+	}
+
+	sum := md5.Sum(body)
+	if got, want := header.Get("Content-MD5"), hex.EncodeToString(sum[:]); got != want {
+		t.Errorf("Content-MD5 = %s, want %s", got, want)
+	}
+
+	date := header.Get("Date")
+	if _, err := time.Parse(time.RFC1123Z, date); err != nil {
+		t.Errorf("Date %q: %v", date, err)
+	}
+
+	want := amojoSignature(testAmojoSecret, strings.Join([]string{
+		http.MethodPost,
+		header.Get("Content-MD5"),
+		header.Get("Content-Type"),
+		date,
+		path,
+	}, "\n"))
+	if got := header.Get("X-Signature"); got != want {
+		t.Errorf("X-Signature = %s, want %s", got, want)
+	}
+}
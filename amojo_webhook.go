@@ -0,0 +1,234 @@
+package amocrm_v4
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AmojoWebhookUser Участник чата во входящем вебхуке
+type AmojoWebhookUser struct {
+	Id       string `json:"id"`
+	ClientId string `json:"client_id"` // ID пользователя во внешней системе
+	RefId    string `json:"ref_id"`
+	Name     string `json:"name"`
+	Avatar   string `json:"avatar"`
+	Phone    string `json:"phone"`
+	Email    string `json:"email"`
+}
+
+// AmojoWebhookContent Содержимое сообщения. Заполнены поля, соответствующие Type:
+// Text для text, Media и FileName для picture, file, video, voice, audio и sticker,
+// Location для location, Contact для contact.
+type AmojoWebhookContent struct {
+	Id        string           `json:"id"`
+	Type      AmojoMessageType `json:"type"`
+	Text      string           `json:"text"`
+	Tag       string           `json:"tag"`
+	Media     string           `json:"media"`
+	Thumbnail string           `json:"thumbnail"`
+	FileName  string           `json:"file_name"`
+	FileSize  int              `json:"file_size"`
+	Location  *AmojoLocation   `json:"location"`
+	Contact   *AmojoContact    `json:"contact"`
+}
+
+// AmojoWebhookMessage Сообщение, которое оператор отправил из amoCRM
+type AmojoWebhookMessage struct {
+	AccountId string    `json:"-"` // amojo_id аккаунта
+	ScopeId   string    `json:"-"` // scope_id канала из адреса вебхука
+	Time      time.Time `json:"-"`
+
+	Conversation struct {
+		Id       string `json:"id"`        // ID чата в amojo
+		ClientId string `json:"client_id"` // ID чата во внешней системе
+	} `json:"conversation"`
+	Source struct {
+		ExternalId string `json:"external_id"`
+	} `json:"source"`
+	Sender        AmojoWebhookUser    `json:"sender"`
+	Receiver      AmojoWebhookUser    `json:"receiver"`
+	Timestamp     int64               `json:"timestamp"`
+	MsecTimestamp int64               `json:"msec_timestamp"`
+	Message       AmojoWebhookContent `json:"message"`
+}
+
+// AmojoWebhookCallback Обработчик сообщения из вебхука API чатов
+type AmojoWebhookCallback func(m *AmojoWebhookMessage)
+
+// AmojoWebhookHandler http.Handler для приема вебхуков канала API чатов. Проверяет подпись X-Signature
+// секретом канала, отвечает 200 сразу после разбора запроса и вызывает обработчики пулом воркеров.
+type AmojoWebhookHandler struct {
+	channelSecret string
+
+	mu        sync.RWMutex
+	callbacks map[AmojoMessageType][]AmojoWebhookCallback
+	all       []AmojoWebhookCallback
+	queue     chan *AmojoWebhookMessage
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+type amojoWebhookBody struct {
+	AccountId string              `json:"account_id"`
+	Time      int64               `json:"time"`
+	Message   AmojoWebhookMessage `json:"message"`
+}
+
+// NewAmojoWebhookHandler Создает обработчик вебхуков канала с workers воркерами и очередью на queueSize сообщений.
+// Нулевые значения заменяются значениями по умолчанию.
+func NewAmojoWebhookHandler(channelSecret string, workers, queueSize int) *AmojoWebhookHandler {
+	if workers <= 0 {
+		workers = defaultWebhookWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultWebhookQueueSize
+	}
+
+	h := &AmojoWebhookHandler{
+		channelSecret: channelSecret,
+		callbacks:     make(map[AmojoMessageType][]AmojoWebhookCallback),
+		queue:         make(chan *AmojoWebhookMessage, queueSize),
+	}
+
+	h.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go h.worker()
+	}
+
+	return h
+}
+
+// On Регистрирует обработчик для типа сообщения
+func (h *AmojoWebhookHandler) On(t AmojoMessageType, fn AmojoWebhookCallback) *AmojoWebhookHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.callbacks[t] = append(h.callbacks[t], fn)
+
+	return h
+}
+
+// OnAny Регистрирует обработчик для всех сообщений
+func (h *AmojoWebhookHandler) OnAny(fn AmojoWebhookCallback) *AmojoWebhookHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.all = append(h.all, fn)
+
+	return h
+}
+
+// Close Прекращает прием вебхуков и дожидается обработки уже принятых
+func (h *AmojoWebhookHandler) Close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	close(h.queue)
+	h.mu.Unlock()
+
+	h.wg.Wait()
+}
+
+func (h *AmojoWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, webhookMaxBody))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if !VerifyAmojoSignature(h.channelSecret, body, r.Header.Get("X-Signature")) {
+		log.Warnf("Отклонен вебхук API чатов: подпись не совпадает")
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	payload := amojoWebhookBody{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	m := &payload.Message
+	m.AccountId = payload.AccountId
+	m.ScopeId = path.Base(r.URL.Path)
+	if payload.Time != 0 {
+		m.Time = time.Unix(payload.Time, 0)
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.closed {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	select {
+	case h.queue <- m:
+		w.WriteHeader(http.StatusOK)
+	default:
+		log.Warnf("очередь вебхуков API чатов заполнена, отброшено сообщение %s", m.Message.Id)
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	}
+}
+
+func (h *AmojoWebhookHandler) worker() {
+	defer h.wg.Done()
+
+	for m := range h.queue {
+		h.dispatch(m)
+	}
+}
+
+func (h *AmojoWebhookHandler) dispatch(m *AmojoWebhookMessage) {
+	h.mu.RLock()
+	callbacks := make([]AmojoWebhookCallback, 0, len(h.callbacks[m.Message.Type])+len(h.all))
+	callbacks = append(callbacks, h.callbacks[m.Message.Type]...)
+	callbacks = append(callbacks, h.all...)
+	h.mu.RUnlock()
+
+	for _, fn := range callbacks {
+		h.call(fn, m)
+	}
+}
+
+func (h *AmojoWebhookHandler) call(fn AmojoWebhookCallback, m *AmojoWebhookMessage) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("паника в обработчике вебхука API чатов %s: %v", m.Message.Type, r)
+		}
+	}()
+
+	fn(m)
+}
+
+// VerifyAmojoSignature Проверяет подпись X-Signature вебхука API чатов: HMAC-SHA1 от тела запроса секретом канала
+func VerifyAmojoSignature(channelSecret string, body []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha1.New, []byte(channelSecret))
+	mac.Write(body)
+
+	return hmac.Equal(expected, mac.Sum(nil))
+}
@@ -0,0 +1,26 @@
+package amocrm_v4
+
+import "testing"
+
+func TestVerifyAmojoSignature(t *testing.T) {
+	body := []byte(`{"account_id":"af9945ff-1490-4cad-807d-945c15d88bec"}`)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		want      bool
+	}{
+		{"valid", testAmojoSecret, "02dd233389450a2d7cf1cc3e7f721ad9d50343c4", true},
+		{"wrong secret", "secret", "02dd233389450a2d7cf1cc3e7f721ad9d50343c4", false},
+		{"wrong signature", testAmojoSecret, "02dd233389450a2d7cf1cc3e7f721ad9d50343c5", false},
+		{"empty", testAmojoSecret, "", false},
+		{"not hex", testAmojoSecret, "signature", false},
+	}
+
+	for _, tt := range tests {
+		if got := VerifyAmojoSignature(tt.secret, body, tt.signature); got != tt.want {
+			t.Errorf("%s: VerifyAmojoSignature() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -21,6 +21,7 @@ type AuthorizationData struct {
 	AppName      string    `gorm:"column:app_name"`
 	RefreshToken string    `gorm:"column:refresh_token"`
 	ExpiresIn    time.Time `gorm:"column:expires_in"`
+	KeyId        string    `gorm:"column:key_id"` // ID ключа шифрования refresh_token, пустой – токен не зашифрован
 }
 
 type authResp struct {
@@ -53,6 +54,9 @@ func createConnection(init *InitAmoConfig, storage *AuthAmoStorageConfig) error
 	client.redirectUri = init.RedirectURI
 	client.client = http.Client{}
 	client.storage = storage
+	if init.OnTokenEvent != nil {
+		client.onTokenEvent = append(client.onTokenEvent, init.OnTokenEvent)
+	}
 
 	err := client.open(init.Code)
 	if err != nil {
@@ -66,13 +70,12 @@ func createConnection(init *InitAmoConfig, storage *AuthAmoStorageConfig) error
 
 func (a *authSettings) open(authCode string) error {
 
-	amoAuthorizationData := AuthorizationData{}
-	result := a.storage.DB.Table(a.storage.TableName).Where("app_name = ?", a.storage.AppName).First(&amoAuthorizationData)
-	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
-		log.Errorf("Ошибка при получении данных об авторизации в АМО: %v", result.Error)
+	amoAuthorizationData, err := getAuthDataFromDB(a.storage)
+	if err != nil && !errors.Is(err, errAuthDataNotFound) {
+		log.Errorf("Ошибка при получении данных об авторизации в АМО: %v", err)
 	}
 
-	if result.RowsAffected == 0 {
+	if amoAuthorizationData == nil {
 		var ret = authResp{}
 		opts := requestOpts{
 			Method: http.MethodPost,
@@ -87,30 +90,23 @@ func (a *authSettings) open(authCode string) error {
 			Ret: &ret,
 		}
 
-		err := httpRequest(opts)
+		err := a.request(opts)
 		if err != nil {
 			return err
 		}
 
 		log.Debugf("Получены данные об авторизации в АМО: %+v", ret)
 
-		client.accessToken = ret.AccessToken
+		exprIn := time.Now().Add(time.Duration(ret.ExpiresIn) * time.Second).Add(-1 * time.Minute)
+		a.tokenIssued(ret.AccessToken, exprIn)
 
-		log.Debugf("Получен новый access_token: %s", client.accessToken)
+		log.Debugf("Получен новый access_token: %s", ret.AccessToken)
 		log.Debugf("Получен новый refresh_token: %s", ret.RefreshToken)
 		log.Debugf("Получено новое время жизни access_token: %d", ret.ExpiresIn)
 
-		exprIn := time.Now().Add(time.Duration(ret.ExpiresIn) * time.Second).Add(-1 * time.Minute)
-
 		log.Debugf("Время жизни access_token истекает: %s", exprIn)
 
-		amoAuthorizationData = AuthorizationData{
-			AppName:      a.storage.AppName,
-			RefreshToken: ret.RefreshToken,
-			ExpiresIn:    exprIn,
-		}
-
-		err = a.storage.DB.Table(a.storage.TableName).Create(&amoAuthorizationData).Error
+		err = createAuthDataInDB(a.storage, ret.RefreshToken, exprIn)
 		if err != nil {
 			return fmt.Errorf("ошибка при создании записи в БД об авторизации в АМО: %v", err)
 		}
@@ -129,19 +125,20 @@ func (a *authSettings) open(authCode string) error {
 			Ret: &ret,
 		}
 
-		err := httpRequest(opts)
+		err := a.request(opts)
 		if err != nil {
+			a.tokenRefreshFailed(err)
 			return fmt.Errorf("ошибка получения нового access_token: %v", err)
 		}
 
-		client.accessToken = ret.AccessToken
+		exprIn := time.Now().Add(time.Duration(ret.ExpiresIn)*time.Second - 1*time.Minute)
+		a.tokenRefreshed(ret.AccessToken, exprIn)
 		// сохраняем новый refresh token в БД
-		err = updateAuthDataInDB(
-			a.storage.DB, a.storage.TableName, a.storage.AppName, ret.RefreshToken,
-			time.Now().Add(time.Duration(ret.ExpiresIn)*time.Second-1*time.Minute),
-		)
+		err = updateAuthDataInDB(a.storage, ret.RefreshToken, exprIn)
 		if err != nil {
-			return fmt.Errorf("ошибка при сохранении нового refresh_token в БД: %v", err)
+			err = fmt.Errorf("ошибка при сохранении нового refresh_token в БД: %w", err)
+			a.tokenPersistFailed(err)
+			return err
 		}
 
 	}
@@ -155,13 +152,14 @@ func (a *authSettings) refresher() {
 	for {
 		select {
 		case <-ticker.C:
-			auth, err := getAuthDataFromDB(a.storage.DB, a.storage.TableName, a.storage.AppName)
+			auth, err := getAuthDataFromDB(a.storage)
 			if err != nil {
 				log.Errorf("Ошибка при получении данных об авторизации в АМО: %v", err)
 				continue
 			}
 
-			if auth.ExpiresIn.Before(time.Now().Add(-5 * time.Minute)) {
+			// обновляем заранее, чтобы при ошибке обновления успеть сообщить о скором истечении токена
+			if auth.ExpiresIn.Before(time.Now().Add(5 * time.Minute)) {
 				var ret = authResp{}
 				opts := requestOpts{
 					Method: http.MethodPost,
@@ -175,20 +173,27 @@ func (a *authSettings) refresher() {
 					},
 					Ret: &ret,
 				}
-				err = httpRequest(opts)
+				err = a.request(opts)
+				if IsTokenRevoked(err) {
+					log.Errorf("Доступ интеграции отозван, обновление токена остановлено: %v", err)
+					ticker.Stop()
+					a.tokenRevoked(err)
+					a.disconnect(DisconnectEvent{AccountId: a.accountId, Reason: DisconnectTokenRevoked, Err: err})
+					return
+				}
 				if err != nil {
 					log.Errorf("Ошибка при обновлении авторизационного токена: %v", err)
+					a.tokenRefreshFailed(err)
 					continue
 				}
 
-				client.accessToken = ret.AccessToken
+				exprIn := time.Now().Add(time.Duration(ret.ExpiresIn)*time.Second - 1*time.Minute)
+				a.tokenRefreshed(ret.AccessToken, exprIn)
 				// сохраняем новый refresh token в БД
-				err = updateAuthDataInDB(
-					a.storage.DB, a.storage.TableName, a.storage.AppName, ret.RefreshToken,
-					time.Now().Add(time.Duration(ret.ExpiresIn)*time.Second-1*time.Minute),
-				)
+				err = updateAuthDataInDB(a.storage, ret.RefreshToken, exprIn)
 				if err != nil {
 					log.Errorf("Ошибка при сохранении нового refresh token в БД: %v", err)
+					a.tokenPersistFailed(err)
 				}
 			} else {
 				log.Infof("Авторизационный токен истекает %v, обновление не требуется", auth.ExpiresIn)
@@ -197,25 +202,81 @@ func (a *authSettings) refresher() {
 	}
 }
 
-func getAuthDataFromDB(db *gorm.DB, table string, appName string) (*AuthorizationData, error) {
+// errAuthDataNotFound В БД нет записи об авторизации приложения
+var errAuthDataNotFound = errors.New("не найдена запись об авторизации в АМО")
+
+// getAuthDataFromDB Возвращает запись об авторизации приложения с расшифрованным refresh_token
+func getAuthDataFromDB(storage *AuthAmoStorageConfig) (*AuthorizationData, error) {
 	amoAuthorizationData := &AuthorizationData{}
 
-	result := db.Table(table).Where("app_name = ?", appName).First(&amoAuthorizationData)
+	result := storage.DB.Table(storage.TableName).Where("app_name = ?", storage.AppName).First(&amoAuthorizationData)
 	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
 		return nil, result.Error
 	}
 
 	if result.RowsAffected == 0 {
-		return nil, errors.New("не найдена запись об авторизации в АМО")
+		return nil, errAuthDataNotFound
+	}
+
+	if storage.Encryptor != nil {
+		refreshToken, err := storage.Encryptor.Decrypt(amoAuthorizationData.RefreshToken, amoAuthorizationData.KeyId)
+		if err != nil {
+			return nil, err
+		}
+		amoAuthorizationData.RefreshToken = refreshToken
 	}
 
 	return amoAuthorizationData, nil
 }
 
-func updateAuthDataInDB(db *gorm.DB, table string, appName string, refreshToken string, expiresIn time.Time) error {
-	return db.Table(table).Where("app_name = ?", appName).
-		Updates(map[string]interface{}{
-			"refresh_token": refreshToken,
-			"expires_in":    expiresIn,
-		}).Error
+// createAuthDataInDB Создает запись об авторизации приложения
+func createAuthDataInDB(storage *AuthAmoStorageConfig, refreshToken string, expiresIn time.Time) error {
+	refreshToken, keyId, err := encryptRefreshToken(storage, refreshToken)
+	if err != nil {
+		return err
+	}
+
+	db := storage.DB.Table(storage.TableName)
+	if storage.Encryptor == nil {
+		// Таблицы, созданные до появления шифрования, могут не содержать колонку key_id
+		db = db.Omit("key_id")
+	}
+
+	return db.Create(&AuthorizationData{
+		AppName:      storage.AppName,
+		RefreshToken: refreshToken,
+		ExpiresIn:    expiresIn,
+		KeyId:        keyId,
+	}).Error
+}
+
+func updateAuthDataInDB(storage *AuthAmoStorageConfig, refreshToken string, expiresIn time.Time) error {
+	refreshToken, keyId, err := encryptRefreshToken(storage, refreshToken)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{
+		"refresh_token": refreshToken,
+		"expires_in":    expiresIn,
+	}
+	if storage.Encryptor != nil {
+		values["key_id"] = keyId
+	}
+
+	return storage.DB.Table(storage.TableName).Where("app_name = ?", storage.AppName).Updates(values).Error
+}
+
+// encryptRefreshToken Шифрует refresh_token, если для хранилища задан Encryptor
+func encryptRefreshToken(storage *AuthAmoStorageConfig, refreshToken string) (string, string, error) {
+	if storage.Encryptor == nil {
+		return refreshToken, "", nil
+	}
+
+	encrypted, keyId, err := storage.Encryptor.Encrypt(refreshToken)
+	if err != nil {
+		return "", "", fmt.Errorf("ошибка шифрования refresh_token: %v", err)
+	}
+
+	return encrypted, keyId, nil
 }
@@ -0,0 +1,292 @@
+package amocrm_v4
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type (
+	Cl                   struct{ service }
+	CallDirectionType    string
+	CallResultStatusType string
+	Calls                []*call
+)
+
+const (
+	CallInbound  CallDirectionType = "inbound"  // Входящий звонок
+	CallOutbound CallDirectionType = "outbound" // Исходящий звонок
+
+	CallAttached   CallResultStatusType = "attached"    // Звонок добавлен примечанием к найденной по телефону сущности
+	CallToUnsorted CallResultStatusType = "unsorted"    // Сущность по телефону не найдена, звонок добавлен в неразобранное
+	CallNotMatched CallResultStatusType = "not_matched" // Сущность по телефону не найдена, звонок не добавлен
+	CallFailed     CallResultStatusType = "failed"      // Ошибка при добавлении звонка
+
+	// callBatchSize Максимальное количество звонков в одном запросе
+	callBatchSize = 50
+)
+
+type call struct {
+	Direction         CallDirectionType `json:"direction"`                     // Направление звонка. Обязательный параметр
+	Uniq              string            `json:"uniq,omitempty"`                // Уникальный идентификатор звонка
+	Duration          int               `json:"duration"`                      // Длительность звонка в секундах. Обязательный параметр
+	Source            string            `json:"source"`                        // Источник звонка. Обязательный параметр
+	Link              string            `json:"link,omitempty"`                // Ссылка на запись звонка
+	Phone             string            `json:"phone"`                         // Номер телефона, по которому будет произведен поиск сущности. Обязательный параметр
+	CallResult        string            `json:"call_result,omitempty"`         // Результат звонка
+	CallStatus        CallStatusType    `json:"call_status,omitempty"`         // Статус звонка
+	ResponsibleUserId int               `json:"responsible_user_id,omitempty"` // ID пользователя, ответственного за звонок
+	CreatedBy         int               `json:"created_by,omitempty"`          // ID пользователя, создавшего звонок
+	UpdatedBy         int               `json:"updated_by,omitempty"`          // ID пользователя, изменившего звонок
+	CreatedAt         int               `json:"created_at,omitempty"`          // Дата создания звонка, передается в Unix Timestamp
+	UpdatedAt         int               `json:"updated_at,omitempty"`          // Дата изменения звонка, передается в Unix Timestamp
+	RequestId         string            `json:"request_id,omitempty"`          // Поле, которое вернется вам в ответе без изменений и не будет сохранено
+}
+
+type allCalls struct {
+	TotalItems int `json:"_total_items"`
+	Errors     []struct {
+		RequestId string `json:"request_id"`
+		Title     string `json:"title"`
+		Detail    string `json:"detail"`
+		Status    int    `json:"status"`
+	} `json:"errors"`
+	Embedded struct {
+		Calls []struct {
+			Id         int    `json:"id"`          // ID созданного примечания
+			EntityId   int    `json:"entity_id"`   // ID сущности, к которой прикреплен звонок
+			EntityType string `json:"entity_type"` // Тип сущности, к которой прикреплен звонок
+			AccountId  int    `json:"account_id"`
+			RequestId  string `json:"request_id"`
+		} `json:"calls"`
+	} `json:"_embedded"`
+}
+
+// AddCallsOptions Параметры пакетного добавления звонков
+type AddCallsOptions struct {
+	UnsortedFallback bool   // Добавлять в неразобранное звонки, для которых не найдена сущность
+	PipelineId       int    // ID воронки для неразобранного
+	SourceName       string // Название источника для неразобранного
+	ServiceCode      string // Код сервиса телефонии для неразобранного
+}
+
+// CallResult Результат добавления одного звонка
+type CallResult struct {
+	Call        *call
+	Status      CallResultStatusType
+	NoteId      int    // ID созданного примечания, если Status == CallAttached
+	EntityId    int    // ID сущности, к которой прикреплен звонок
+	EntityType  string // Тип сущности, к которой прикреплен звонок
+	UnsortedUid string // UID неразобранного, если Status == CallToUnsorted
+	Err         error  // Ошибка, если Status == CallFailed
+}
+
+func (c Cl) New() *call {
+	return &call{}
+}
+
+// Validate Проверяет, что у звонка заполнены обязательные параметры
+func (cl *call) Validate() error {
+	if cl.Direction != CallInbound && cl.Direction != CallOutbound {
+		return fmt.Errorf("недопустимое направление звонка %q", cl.Direction)
+	}
+	if cl.Phone == "" {
+		return fmt.Errorf("не указан номер телефона звонка")
+	}
+	if cl.Source == "" {
+		return fmt.Errorf("не указан источник звонка")
+	}
+	if cl.CallStatus < 0 || cl.CallStatus > CallStatusBusy {
+		return fmt.Errorf("недопустимый call_status %d", cl.CallStatus)
+	}
+
+	return nil
+}
+
+// Add Метод позволяет пакетно добавлять звонки. amoCRM сам находит контакт, компанию или сделку
+// по номеру телефона и прикрепляет к ней примечание о звонке.
+// Результаты возвращаются в том же порядке, что и переданные звонки.
+func (c Cl) Add(calls Calls, opts *AddCallsOptions) ([]*CallResult, error) {
+	if opts == nil {
+		opts = &AddCallsOptions{}
+	}
+
+	// request_id звонков, переданные вызывающим, сохраняются. Для остальных используется индекс звонка
+	requestIds := make([]string, len(calls))
+	byRequestId := make(map[string]int, len(calls))
+	for i, cl := range calls {
+		if err := cl.Validate(); err != nil {
+			return nil, fmt.Errorf("звонок %d: %v", i, err)
+		}
+
+		requestIds[i] = cl.RequestId
+		if requestIds[i] == "" {
+			requestIds[i] = strconv.Itoa(i)
+		}
+		if _, ok := byRequestId[requestIds[i]]; ok {
+			return nil, fmt.Errorf("звонок %d: повторяющийся request_id %q", i, requestIds[i])
+		}
+		byRequestId[requestIds[i]] = i
+	}
+
+	results := make([]*CallResult, len(calls))
+	var unmatched []int
+
+	for start := 0; start < len(calls); start += callBatchSize {
+		end := start + callBatchSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+
+		batch := make(Calls, 0, end-start)
+		for i := start; i < end; i++ {
+			cl := *calls[i]
+			cl.RequestId = requestIds[i]
+			batch = append(batch, &cl)
+		}
+
+		ret := allCalls{}
+		err := c.api().request(requestOpts{
+			Method:         http.MethodPost,
+			Path:           "/api/v4/calls",
+			DataParameters: &batch,
+			Ret:            &ret,
+			// звонки, для которых не найдена сущность, возвращаются в errors с кодом 207
+			AllowMultiStatus: true,
+		})
+		if err != nil {
+			return results, err
+		}
+
+		for _, r := range ret.Embedded.Calls {
+			i, ok := byRequestId[r.RequestId]
+			if !ok || i < start || i >= end {
+				continue
+			}
+
+			results[i] = &CallResult{
+				Call:       calls[i],
+				Status:     CallAttached,
+				NoteId:     r.Id,
+				EntityId:   r.EntityId,
+				EntityType: r.EntityType,
+			}
+		}
+
+		for _, e := range ret.Errors {
+			i, ok := byRequestId[e.RequestId]
+			if !ok || i < start || i >= end {
+				continue
+			}
+
+			if e.Status == http.StatusNotFound {
+				results[i] = &CallResult{Call: calls[i], Status: CallNotMatched}
+				unmatched = append(unmatched, i)
+				continue
+			}
+
+			results[i] = &CallResult{
+				Call:   calls[i],
+				Status: CallFailed,
+				Err:    fmt.Errorf("%s: %s", e.Title, e.Detail),
+			}
+		}
+
+		for i := start; i < end; i++ {
+			if results[i] == nil {
+				results[i] = &CallResult{
+					Call:   calls[i],
+					Status: CallFailed,
+					Err:    fmt.Errorf("звонок отсутствует в ответе API"),
+				}
+			}
+		}
+	}
+
+	if opts.UnsortedFallback && len(unmatched) > 0 {
+		if err := c.toUnsorted(calls, unmatched, results, opts); err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+// toUnsorted Добавляет звонки, для которых не найдена сущность, в неразобранное
+func (c Cl) toUnsorted(calls Calls, idx []int, results []*CallResult, opts *AddCallsOptions) error {
+	items := make(UnsortedList, 0, len(idx))
+	for _, i := range idx {
+		cl := calls[i]
+
+		calledAt := cl.CreatedAt
+		if calledAt == 0 {
+			calledAt = int(time.Now().Unix())
+		}
+
+		// source_uid обязателен для неразобранного, а uniq у звонка может быть не указан
+		uniq := cl.Uniq
+		if uniq == "" {
+			var err error
+			if uniq, err = randomHex(16); err != nil {
+				return err
+			}
+		}
+
+		item := Uns{c.service}.NewSip(&UnsortedSipMetadata{
+			From:              cl.Phone,
+			Phone:             cl.Phone,
+			CalledAt:          calledAt,
+			Duration:          cl.Duration,
+			Link:              cl.Link,
+			ServiceCode:       opts.ServiceCode,
+			IsCallEventNeeded: cl.Direction == CallInbound,
+			Uniq:              uniq,
+		})
+		item.SourceUid = uniq
+		item.SourceName = opts.SourceName
+		item.PipelineId = opts.PipelineId
+		item.CreatedAt = calledAt
+		item.RequestId = strconv.Itoa(i)
+		phoneCode := "PHONE"
+		item.Embedded.Leads = []*lead{{Name: fmt.Sprintf("Звонок с %s", cl.Phone)}}
+		item.Embedded.Contacts = []*contact{{
+			Name: cl.Phone,
+			CustomFieldsValues: CustomFields{{
+				FieldCode: &phoneCode,
+				Values:    []CustomFieldValue{MultitextValue(cl.Phone, "WORK")},
+			}},
+		}}
+
+		items = append(items, item)
+	}
+
+	ret, err := Uns{c.service}.CreateSip(items)
+	if err != nil {
+		return err
+	}
+
+	for _, u := range ret.Embedded.Unsorted {
+		i, err := strconv.Atoi(u.RequestId)
+		if err != nil || i < 0 || i >= len(results) {
+			continue
+		}
+
+		results[i].Status = CallToUnsorted
+		results[i].UnsortedUid = u.Uid
+	}
+
+	return nil
+}
+
+// randomHex Возвращает n случайных байт в hex
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
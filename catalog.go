@@ -4,10 +4,11 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 type (
-	Ctg             struct{}
+	Ctg             struct{ service }
 	CatalogType     string
 	Catalogs        []*catalog
 	Elements        []*element
@@ -20,11 +21,12 @@ type GetCatalogsQueryParams struct {
 }
 
 type GetCatalogElementsQueryParams struct {
-	Page       int             `url:"page"`         //Страница выборки
-	Limit      int             `url:"limit"`        //Количество возвращаемых сущностей за один запрос (Максимум – 250)
-	Query      string          `url:"query"`        //Поисковый запрос (Осуществляет поиск по заполненным полям сущности)
-	FilterByID string          `url:"filter_by_id"` //Фильтр по ID элемента. Можно передать как один ID, так и массив из нескольких ID
-	With       ElementWithType `url:"with"`         //Поля для выборки
+	Page        int             `url:"page,omitempty"`         //Страница выборки
+	Limit       int             `url:"limit,omitempty"`        //Количество возвращаемых сущностей за один запрос (Максимум – 250)
+	Query       string          `url:"query,omitempty"`        //Поисковый запрос (Осуществляет поиск по заполненным полям сущности)
+	FilterByID  string          `url:"filter_by_id,omitempty"` //Фильтр по ID элемента. Можно передать как один ID, так и массив из нескольких ID
+	FilterByIDs []int           `url:"filter[id][],omitempty"` //Фильтр по нескольким ID элементов
+	With        ElementWithType `url:"with,omitempty"`         //Поля для выборки
 }
 
 const (
@@ -36,6 +38,8 @@ const (
 )
 
 type catalog struct {
+	service
+
 	Id              int         `json:"id,omitempty"`                // ID списка
 	Name            string      `json:"name,omitempty"`              // Название списка
 	CreatedBy       int         `json:"created_by,omitempty"`        // ID пользователя, создавший список
@@ -62,56 +66,81 @@ type allCatalogs struct {
 	Page     int   `json:"_page"`
 	Links    links `json:"_links"`
 	Embedded struct {
-		Catalogs []*catalog `json:"catalogs"`
+		Catalogs Catalogs `json:"catalogs"`
 	} `json:"_embedded"`
 }
 
 type element struct {
-	Id                 int           `json:"id,omitempty"`         //ID элемента списка
-	CatalogId          int           `json:"catalog_id,omitempty"` //ID списка
-	Name               string        `json:"name,omitempty"`       //Название элемента
-	CreatedBy          int           `json:"created_by,omitempty"` //ID пользователя, создавший элемент
-	UpdatedBy          int           `json:"updated_by,omitempty"` //ID пользователя, изменивший элемент последним
-	CreatedAt          int           `json:"created_at,omitempty"` //Дата создания элемента, передается в Unix Timestamp
-	UpdatedAt          int           `json:"updated_at,omitempty"` //Дата изменения элемента, передается в Unix Timestamp
-	IsDeleted          bool          `json:"is_deleted,omitempty"` //Удален ли элемент
-	CustomFieldsValues []CustomField `json:"custom_fields_values"`
-	AccountId          int           `json:"account_id"`
+	service
+
+	Id                 int          `json:"id,omitempty"`         //ID элемента списка
+	CatalogId          int          `json:"catalog_id,omitempty"` //ID списка
+	Name               string       `json:"name,omitempty"`       //Название элемента
+	CreatedBy          int          `json:"created_by,omitempty"` //ID пользователя, создавший элемент
+	UpdatedBy          int          `json:"updated_by,omitempty"` //ID пользователя, изменивший элемент последним
+	CreatedAt          int          `json:"created_at,omitempty"` //Дата создания элемента, передается в Unix Timestamp
+	UpdatedAt          int          `json:"updated_at,omitempty"` //Дата изменения элемента, передается в Unix Timestamp
+	IsDeleted          bool         `json:"is_deleted,omitempty"` //Удален ли элемент
+	CustomFieldsValues CustomFields `json:"custom_fields_values,omitempty"`
+	AccountId          int          `json:"account_id,omitempty"`
+	RequestId          string       `json:"request_id,omitempty"`   //Поле, которое вернется вам в ответе без изменений и не будет сохранено
+	InvoiceLink        *string      `json:"invoice_link,omitempty"` //Требуется GET параметр with. Ссылка на печатную форму счета
 	Links              struct {
 		Self struct {
-			Href string `json:"href"`
-		} `json:"self"`
-	} `json:"_links"`
+			Href string `json:"href,omitempty"`
+		} `json:"self,omitempty"`
+	} `json:"_links,omitempty"`
 }
 
 type allCatalogElements struct {
 	Page     int   `json:"_page"`
 	Links    links `json:"_links"`
 	Embedded struct {
-		Elements []*element `json:"elements"`
+		Elements Elements `json:"elements"`
 	} `json:"_embedded"`
 }
 
-//All Метод позволяет получить доступные списки в аккаунте.
+// All Метод позволяет получить доступные списки в аккаунте. Запрашиваются все страницы.
 func (c Ctg) All() (*allCatalogs, error) {
 	req := GetCatalogsQueryParams{
 		Limit: 250,
 	}
 	ret := allCatalogs{}
 
-	return &ret, httpRequest(requestOpts{
-		Method:        http.MethodGet,
-		Path:          "/api/v4/catalogs",
-		URLParameters: req,
-		Ret:           &ret,
-	})
+	for {
+		var tmpCatalogs allCatalogs
+
+		err := c.api().request(requestOpts{
+			Method:        http.MethodGet,
+			Path:          "/api/v4/catalogs",
+			URLParameters: req,
+			Ret:           &tmpCatalogs,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		ret.Page = tmpCatalogs.Page
+		ret.Links = tmpCatalogs.Links
+		ret.Embedded.Catalogs = append(ret.Embedded.Catalogs, tmpCatalogs.Embedded.Catalogs...)
+
+		if len(tmpCatalogs.Links.Next.Href) > 0 {
+			req.Page = tmpCatalogs.Page + 1
+		} else {
+			break
+		}
+	}
+
+	ret.Embedded.Catalogs.bind(c.service)
+
+	return &ret, nil
 }
 
 // ByID Метод позволяет получить данные конкретного списка по ID.
 func (c Ctg) ByID(id int) (*catalog, error) {
-	ret := catalog{}
+	ret := catalog{service: c.service}
 
-	return &ret, httpRequest(requestOpts{
+	return &ret, c.api().request(requestOpts{
 		Method: http.MethodGet,
 		Path:   "/api/v4/catalogs/" + strconv.Itoa(id),
 		Ret:    &ret,
@@ -119,23 +148,146 @@ func (c Ctg) ByID(id int) (*catalog, error) {
 }
 
 func (c Ctg) New() *catalog {
-	return &catalog{}
+	return &catalog{service: c.service}
 }
 
 // Create Метод позволяет добавлять списки в аккаунт пакетно.
 func (c Ctg) Create(catalogs Catalogs) (*allCatalogs, error) {
 	ret := allCatalogs{}
 
-	return &ret, httpRequest(requestOpts{
+	err := c.api().request(requestOpts{
 		Method:         http.MethodPost,
 		Path:           "/api/v4/catalogs",
 		DataParameters: &catalogs,
 		Ret:            &ret,
 	})
+
+	ret.Embedded.Catalogs.bind(c.service)
+
+	return &ret, err
+}
+
+// Update Метод позволяет редактировать списки пакетно.
+func (c Ctg) Update(catalogs Catalogs) (*allCatalogs, error) {
+	ret := allCatalogs{}
+
+	err := c.api().request(requestOpts{
+		Method:         http.MethodPatch,
+		Path:           "/api/v4/catalogs",
+		DataParameters: &catalogs,
+		Ret:            &ret,
+	})
+
+	ret.Embedded.Catalogs.bind(c.service)
+
+	return &ret, err
+}
+
+// Delete Метод позволяет удалять списки пакетно.
+func (c Ctg) Delete(ids ...int) error {
+	return c.api().request(requestOpts{
+		Method:         http.MethodDelete,
+		Path:           "/api/v4/catalogs",
+		DataParameters: idsBody(ids),
+	})
+}
+
+// Update Метод позволяет редактировать конкретный список.
+func (c *catalog) Update() (*catalog, error) {
+	ret := catalog{service: c.service}
+
+	return &ret, c.api().request(requestOpts{
+		Method:         http.MethodPatch,
+		Path:           "/api/v4/catalogs/" + strconv.Itoa(c.Id),
+		DataParameters: &c,
+		Ret:            &ret,
+	})
+}
+
+func (c *catalog) NewElement() *element {
+	return &element{service: c.service, CatalogId: c.Id}
+}
+
+// CreateElements Метод позволяет добавлять элементы списка пакетно.
+func (c *catalog) CreateElements(elements Elements) (Elements, error) {
+	ret := allCatalogElements{}
+
+	err := c.api().request(requestOpts{
+		Method:         http.MethodPost,
+		Path:           fmt.Sprintf("/api/v4/catalogs/%d/elements", c.Id),
+		DataParameters: &elements,
+		Ret:            &ret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret.Embedded.Elements.bind(c.service)
+
+	return ret.Embedded.Elements, nil
 }
 
-//TODO: PATCH /api/v4/catalogs
-//TODO: PATCH /api/v4/catalogs/{id}
+// UpdateElements Метод позволяет редактировать элементы списка пакетно.
+func (c *catalog) UpdateElements(elements Elements) (Elements, error) {
+	ret := allCatalogElements{}
+
+	err := c.api().request(requestOpts{
+		Method:         http.MethodPatch,
+		Path:           fmt.Sprintf("/api/v4/catalogs/%d/elements", c.Id),
+		DataParameters: &elements,
+		Ret:            &ret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret.Embedded.Elements.bind(c.service)
+
+	return ret.Embedded.Elements, nil
+}
+
+// DeleteElements Метод позволяет удалять элементы списка пакетно.
+func (c *catalog) DeleteElements(ids ...int) error {
+	return c.api().request(requestOpts{
+		Method:         http.MethodDelete,
+		Path:           fmt.Sprintf("/api/v4/catalogs/%d/elements", c.Id),
+		DataParameters: idsBody(ids),
+	})
+}
+
+// ElementByID Метод позволяет получить элемент списка по ID.
+func (c *catalog) ElementByID(id int, with ...ElementWithType) (*element, error) {
+	ret := element{service: c.service}
+
+	return &ret, c.api().request(requestOpts{
+		Method:        http.MethodGet,
+		Path:          fmt.Sprintf("/api/v4/catalogs/%d/elements/%d", c.Id, id),
+		URLParameters: &GetCatalogElementsQueryParams{With: joinElementWith(with)},
+		Ret:           &ret,
+	})
+}
+
+// joinElementWith Объединяет несколько значений with через запятую
+func joinElementWith(with []ElementWithType) ElementWithType {
+	values := make([]string, 0, len(with))
+	for _, w := range with {
+		values = append(values, string(w))
+	}
+
+	return ElementWithType(strings.Join(values, ","))
+}
+
+// Update Метод позволяет редактировать конкретный элемент списка.
+func (e *element) Update() (*element, error) {
+	ret := element{service: e.service}
+
+	return &ret, e.api().request(requestOpts{
+		Method:         http.MethodPatch,
+		Path:           fmt.Sprintf("/api/v4/catalogs/%d/elements/%d", e.CatalogId, e.Id),
+		DataParameters: &e,
+		Ret:            &ret,
+	})
+}
 
 func (c *catalog) AllElements() (Elements, error) {
 	return c.multiplyRequest(&GetCatalogElementsQueryParams{
@@ -162,20 +314,21 @@ func (c *catalog) multiplyRequest(opts *GetCatalogElementsQueryParams) (Elements
 		var tmpElements allCatalogElements
 
 		path := fmt.Sprintf("/api/v4/catalogs/%d/elements", c.Id)
-		err := httpRequest(requestOpts{
+		err := c.api().request(requestOpts{
 			Method:        http.MethodGet,
 			Path:          path,
 			URLParameters: &opts,
 			Ret:           &tmpElements,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("ошибка обработки запроса %s: %s", path, err)
+			return nil, fmt.Errorf("ошибка обработки запроса %s: %w", path, err)
 		}
 
+		tmpElements.Embedded.Elements.bind(c.service)
 		elements = append(elements, tmpElements.Embedded.Elements...)
 
 		if len(tmpElements.Links.Next.Href) > 0 {
-			opts.Page = opts.Page + 1
+			opts.Page = tmpElements.Page + 1
 		} else {
 			break
 		}
@@ -184,6 +337,82 @@ func (c *catalog) multiplyRequest(opts *GetCatalogElementsQueryParams) (Elements
 	return elements, nil
 }
 
-func GetAllProducts() {
+// Product Элемент списка товаров с разобранными ценой и артикулом
+type Product struct {
+	*element
+	Sku         string  // Артикул (поле SKU)
+	Price       float64 // Цена (поле PRICE)
+	Description string  // Описание (поле DESCRIPTION)
+	Unit        string  // Единица измерения (поле UNIT)
+	ExternalId  string  // Внешний ID (поле EXTERNAL_ID)
+}
+
+// ByType Метод возвращает первый список указанного типа.
+func (c Ctg) ByType(catalogType CatalogType) (*catalog, error) {
+	catalogs, err := c.All()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ctg := range catalogs.Embedded.Catalogs {
+		if ctg.Type == catalogType {
+			return ctg, nil
+		}
+	}
+
+	return nil, fmt.Errorf("список типа %s не найден в аккаунте", catalogType)
+}
+
+// Products Метод возвращает все товары из списка типа products.
+func (c Ctg) Products() ([]*Product, error) {
+	ctg, err := c.ByType(CatalogProducts)
+	if err != nil {
+		return nil, err
+	}
+
+	elements, err := ctg.AllElements()
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]*Product, 0, len(elements))
+	for _, e := range elements {
+		price, err := e.FieldByCode("PRICE").Float()
+		if err != nil {
+			return nil, fmt.Errorf("ошибка разбора цены товара %d: %v", e.Id, err)
+		}
+
+		products = append(products, &Product{
+			element:     e,
+			Sku:         e.FieldByCode("SKU").String(),
+			Price:       price,
+			Description: e.FieldByCode("DESCRIPTION").String(),
+			Unit:        e.FieldByCode("UNIT").String(),
+			ExternalId:  e.FieldByCode("EXTERNAL_ID").String(),
+		})
+	}
+
+	return products, nil
+}
+
+func (c Catalogs) bind(s service) {
+	for _, ctg := range c {
+		ctg.service = s
+	}
+}
+
+func (e Elements) bind(s service) {
+	for _, el := range e {
+		el.service = s
+	}
+}
+
+// idsBody Тело запроса на удаление сущностей по ID
+func idsBody(ids []int) []map[string]int {
+	body := make([]map[string]int, 0, len(ids))
+	for _, id := range ids {
+		body = append(body, map[string]int{"id": id})
+	}
 
+	return body
 }
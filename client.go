@@ -3,13 +3,27 @@ package amocrm_v4
 import (
 	"gorm.io/gorm"
 	"net/http"
+	"sync"
+	"time"
 )
 
 type Amo struct {
-	Contact Ct
-	Lead    Ld
-	Task    Tsk
-	Catalog Ctg
+	service
+
+	Contact      Ct
+	Lead         Ld
+	Task         Tsk
+	Catalog      Ctg
+	CustomFields Cf
+	Tag          Tg
+	Links        Lnk
+	Company      Cmp
+	Unsorted     Uns
+	Events       Ev
+	Note         Nt
+	Calls        Cl
+	Invoices     Inv
+	Webhooks     Wh
 }
 
 type authSettings struct {
@@ -19,7 +33,24 @@ type authSettings struct {
 	endpoint          string
 	redirectUri       string
 	accessToken       string
+	expiresAt         time.Time // Время, до которого действует accessToken
 	storage           *AuthAmoStorageConfig
+
+	accountId    int                // ID аккаунта, если клиент выдан TokenManager
+	disconnected bool               // Интеграция отключена в аккаунте
+	onDisconnect DisconnectCallback // Обработчик отключения интеграции
+	mu           sync.RWMutex       // Защищает accessToken, expiresAt, disconnected, onDisconnect и поля событий токена
+	refreshMu    sync.Mutex         // Не дает обновлять токен аккаунта одновременно из нескольких горутин
+	account      accountCache
+
+	onTokenEvent     []TokenEventCallback // Обработчики событий токена
+	refreshFailures  int                  // Количество неудачных обновлений токена подряд
+	expiringNotified time.Time            // Срок действия токена, о скором истечении которого уже сообщено
+}
+
+// service Аккаунт, в который сервис отправляет запросы. Нулевое значение означает клиент, созданный NewClient.
+type service struct {
+	auth *authSettings
 }
 
 type InitAmoConfig struct {
@@ -28,12 +59,18 @@ type InitAmoConfig struct {
 	ClientSecret string `json:"client_secret"`
 	Code         string `json:"code"`
 	RedirectURI  string `json:"redirect_uri"`
+
+	// OnTokenEvent Обработчик событий токена, в том числе получения токена при создании клиента
+	OnTokenEvent TokenEventCallback `json:"-"`
 }
 
 type AuthAmoStorageConfig struct {
 	DB        *gorm.DB
 	TableName string
 	AppName   string
+
+	// Encryptor Шифрует refresh_token в БД. Требует колонку key_id в таблице, без него токен хранится открыто
+	Encryptor *TokenEncryptor
 }
 
 type AmoAuthorizationDataStorage struct {
@@ -46,5 +83,71 @@ func NewClient(initConfig *InitAmoConfig, storageConfig *AuthAmoStorageConfig) *
 	if err != nil {
 		panic(err)
 	}
-	return &Amo{}
+	return newAmo(&client)
+}
+
+// newAmo Возвращает клиент, все сервисы которого работают с аккаунтом a
+func newAmo(a *authSettings) *Amo {
+	s := service{auth: a}
+
+	return &Amo{
+		service:      s,
+		Contact:      Ct{s},
+		Lead:         Ld{s},
+		Task:         Tsk{s},
+		Catalog:      Ctg{s},
+		CustomFields: Cf{s},
+		Tag:          Tg{s},
+		Links:        Lnk{s},
+		Company:      Cmp{s},
+		Unsorted:     Uns{s},
+		Events:       Ev{s},
+		Note:         Nt{s},
+		Calls:        Cl{s},
+		Invoices:     Inv{s},
+		Webhooks:     Wh{s},
+	}
+}
+
+// api Возвращает настройки аккаунта сервиса. Если сервис не привязан к аккаунту,
+// используется клиент NewClient; запросы через него до вызова NewClient возвращают ErrNoClient.
+func (s service) api() *authSettings {
+	if s.auth == nil {
+		return &client
+	}
+
+	return s.auth
+}
+
+// initialized Проверяет, что настройки аккаунта заполнены (для клиента NewClient – что он создан)
+func (a *authSettings) initialized() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.endpoint != ""
+}
+
+// token Возвращает текущий access_token аккаунта
+func (a *authSettings) token() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.accessToken
+}
+
+// setToken Сохраняет новый access_token аккаунта
+func (a *authSettings) setToken(accessToken string, expiresAt time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.accessToken = accessToken
+	a.expiresAt = expiresAt
+}
+
+// tokenExpiresAt Возвращает время, до которого действует access_token
+func (a *authSettings) tokenExpiresAt() time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.expiresAt
 }
@@ -0,0 +1,69 @@
+package amocrm_v4
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type Cmp struct{ service }
+
+type company struct {
+	service
+
+	Id                 int          `json:"id,omitempty"`                  //ID компании
+	Name               string       `json:"name,omitempty"`                //Название компании
+	ResponsibleUserId  int          `json:"responsible_user_id,omitempty"` //ID пользователя, ответственного за компанию
+	GroupId            int          `json:"group_id,omitempty"`            //ID группы, в которой состоит ответственный пользователь за компанию
+	CreatedBy          int          `json:"created_by,omitempty"`          //ID пользователя, создавший компанию
+	UpdatedBy          int          `json:"updated_by,omitempty"`          //ID пользователя, изменивший компанию
+	CreatedAt          int          `json:"created_at,omitempty"`          //Дата создания компании, передается в Unix Timestamp
+	UpdatedAt          int          `json:"updated_at,omitempty"`          //Дата изменения компании, передается в Unix Timestamp
+	ClosestTaskAt      interface{}  `json:"closest_task_at,omitempty"`     //Дата ближайшей задачи к выполнению, передается в Unix Timestamp
+	IsDeleted          bool         `json:"is_deleted,omitempty"`          //Удалена ли компания
+	CustomFieldsValues CustomFields `json:"custom_fields_values,omitempty"`
+	AccountId          int          `json:"account_id,omitempty"`
+	Links              links        `json:"_links,omitempty"`
+	Embedded           struct {
+		Tags []Tag `json:"tags,omitempty"`
+	} `json:"_embedded,omitempty"`
+}
+
+func (c Cmp) New() *company {
+	return &company{service: c.service}
+}
+
+func (cmp *company) NewNote() *note {
+	return &note{
+		service:    cmp.service,
+		EntityId:   cmp.Id,
+		EntityType: NoteEntityTypeCompany,
+	}
+}
+
+func (c Cmp) ByID(id int) (*company, error) {
+	var cmp *company
+
+	err := c.api().request(requestOpts{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("/api/v4/companies/%d", id),
+		Ret:    &cmp,
+	})
+	// на отсутствующую сущность amoCRM отвечает 204 без тела
+	if err != nil || cmp == nil {
+		return nil, err
+	}
+
+	cmp.service = c.service
+
+	return cmp, nil
+}
+
+// FieldByID Возвращает дополнительное поле компании по ID
+func (cmp *company) FieldByID(id int) *CustomField {
+	return cmp.CustomFieldsValues.ByID(id)
+}
+
+// FieldByCode Возвращает дополнительное поле компании по коду
+func (cmp *company) FieldByCode(code string) *CustomField {
+	return cmp.CustomFieldsValues.ByCode(code)
+}
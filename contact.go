@@ -5,8 +5,7 @@ import (
 	"net/http"
 )
 
-type Ct struct{}
-type contactNote note
+type Ct struct{ service }
 
 type ContactWithType string
 
@@ -26,22 +25,24 @@ type GetContactsQueryParams struct {
 }
 
 type contact struct {
-	Id                 int           `json:"id"`
-	Name               string        `json:"name"`
-	FirstName          string        `json:"first_name"`
-	LastName           string        `json:"last_name"`
-	ResponsibleUserId  int           `json:"responsible_user_id"`
-	GroupId            int           `json:"group_id"`
-	CreatedBy          int           `json:"created_by"`
-	UpdatedBy          int           `json:"updated_by"`
-	CreatedAt          int           `json:"created_at"`
-	UpdatedAt          int           `json:"updated_at"`
-	ClosestTaskAt      interface{}   `json:"closest_task_at"`
-	IsDeleted          bool          `json:"is_deleted,omitempty"`
-	IsUnsorted         bool          `json:"is_unsorted,omitempty"`
-	CustomFieldsValues []CustomField `json:"custom_fields_values"`
-	AccountId          int           `json:"account_id"`
-	Links              links         `json:"_links"`
+	service
+
+	Id                 int          `json:"id"`
+	Name               string       `json:"name"`
+	FirstName          string       `json:"first_name"`
+	LastName           string       `json:"last_name"`
+	ResponsibleUserId  int          `json:"responsible_user_id"`
+	GroupId            int          `json:"group_id"`
+	CreatedBy          int          `json:"created_by"`
+	UpdatedBy          int          `json:"updated_by"`
+	CreatedAt          int          `json:"created_at"`
+	UpdatedAt          int          `json:"updated_at"`
+	ClosestTaskAt      interface{}  `json:"closest_task_at"`
+	IsDeleted          bool         `json:"is_deleted,omitempty"`
+	IsUnsorted         bool         `json:"is_unsorted,omitempty"`
+	CustomFieldsValues CustomFields `json:"custom_fields_values"`
+	AccountId          int          `json:"account_id"`
+	Links              links        `json:"_links"`
 	Embedded           struct {
 		Customers       []interface{} `json:"customers"`
 		Leads           []*lead       `json:"leads"`
@@ -59,21 +60,14 @@ type allContacts struct {
 	} `json:"_embedded"`
 }
 
-type allContactNotes struct {
-	Page     int   `json:"_page"`
-	Links    links `json:"_links"`
-	Embedded struct {
-		Notes []*contactNote `json:"notes"`
-	} `json:"_embedded"`
-}
-
 // New Method creates empty struct
 func (c Ct) New() *contact {
-	return &contact{}
+	return &contact{service: c.service}
 }
 
 func (ct *contact) NewTask() *task {
 	return &task{
+		service:    ct.service,
 		EntityType: TaskForContact,
 		EntityId:   ct.Id,
 	}
@@ -81,6 +75,7 @@ func (ct *contact) NewTask() *task {
 
 func (ct *contact) NewNote() *note {
 	return &note{
+		service:    ct.service,
 		EntityId:   ct.Id,
 		EntityType: NoteEntityTypeContact,
 	}
@@ -117,59 +112,31 @@ func (c Ct) ByID(id int, with []ContactWithType) (*contact, error) {
 		With: with,
 	}
 
-	err := httpRequest(requestOpts{
+	err := c.api().request(requestOpts{
 		Method:        http.MethodGet,
 		Path:          fmt.Sprintf("/api/v4/contacts/%d", id),
 		URLParameters: &opts,
 		Ret:           &ct,
 	})
-	if err != nil {
+	// на отсутствующую сущность amoCRM отвечает 204 без тела
+	if err != nil || ct == nil {
 		return nil, err
 	}
 
+	ct.bind(c.service)
+
 	return ct, nil
 }
 
-func (ct *contact) Notes(params *GetNotesQueryParams) ([]*contactNote, error) {
-	notes, err := ct.noteMultiplyRequest(params)
-	if err != nil {
-		return nil, err
-	}
-
-	return notes, nil
+// bind Привязывает контакт и вложенные сделки к аккаунту
+func (ct *contact) bind(s service) {
+	ct.service = s
+	Leads(ct.Embedded.Leads).bind(s)
 }
 
-func (ct *contact) noteMultiplyRequest(opts *GetNotesQueryParams) ([]*contactNote, error) {
-	var notes []*contactNote
-
-	if opts.Limit == 0 {
-		opts.Limit = 250
-	}
-
-	for {
-		var tmpNotes allContactNotes
-
-		path := fmt.Sprintf("/api/v4/contacts/%d/notes", ct.Id)
-		err := httpRequest(requestOpts{
-			Method:        http.MethodGet,
-			Path:          path,
-			URLParameters: &opts,
-			Ret:           &tmpNotes,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("ошибка обработки запроса %s: %s", path, err)
-		}
-
-		notes = append(notes, tmpNotes.Embedded.Notes...)
-
-		if len(tmpNotes.Links.Next.Href) > 0 {
-			opts.Page = opts.Page + 1
-		} else {
-			break
-		}
-	}
-
-	return notes, nil
+// Notes Возвращает примечания контакта
+func (ct *contact) Notes(params *GetNotesQueryParams) (Notes, error) {
+	return Nt{ct.service}.ByEntity(NoteEntityTypeContact, ct.Id, params)
 }
 
 func (c Ct) multiplyRequest(opts *GetContactsQueryParams) ([]*contact, error) {
@@ -180,7 +147,7 @@ func (c Ct) multiplyRequest(opts *GetContactsQueryParams) ([]*contact, error) {
 	for {
 		var tmpContacts allContacts
 
-		err := httpRequest(requestOpts{
+		err := c.api().request(requestOpts{
 			Method:        http.MethodGet,
 			Path:          path,
 			URLParameters: &opts,
@@ -190,6 +157,9 @@ func (c Ct) multiplyRequest(opts *GetContactsQueryParams) ([]*contact, error) {
 			return nil, err
 		}
 
+		for _, ct := range tmpContacts.Embedded.Contacts {
+			ct.bind(c.service)
+		}
 		contacts = append(contacts, tmpContacts.Embedded.Contacts...)
 
 		if len(tmpContacts.Links.Next.Href) > 0 {
@@ -1,13 +1,403 @@
 package amocrm_v4
 
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type (
+	Cf                    struct{ service }
+	CustomFieldEntityType string
+	CustomFieldType       string
+	CustomFieldDefs       []*customFieldDef
+	CustomFieldGroups     []*customFieldGroup
+)
+
+const (
+	CustomFieldsForLeads     CustomFieldEntityType = "leads"
+	CustomFieldsForContacts  CustomFieldEntityType = "contacts"
+	CustomFieldsForCompanies CustomFieldEntityType = "companies"
+	CustomFieldsForCustomers CustomFieldEntityType = "customers"
+	CustomFieldsForSegments  CustomFieldEntityType = "customers/segments"
+)
+
+const (
+	CustomFieldText         CustomFieldType = "text"          // Текст
+	CustomFieldNumeric      CustomFieldType = "numeric"       // Число
+	CustomFieldCheckbox     CustomFieldType = "checkbox"      // Флаг
+	CustomFieldSelect       CustomFieldType = "select"        // Список
+	CustomFieldMultiselect  CustomFieldType = "multiselect"   // Мультисписок
+	CustomFieldDate         CustomFieldType = "date"          // Дата
+	CustomFieldUrl          CustomFieldType = "url"           // Ссылка
+	CustomFieldTextarea     CustomFieldType = "textarea"      // Текстовая область
+	CustomFieldRadiobutton  CustomFieldType = "radiobutton"   // Переключатель
+	CustomFieldStreetAddr   CustomFieldType = "streetaddress" // Короткий адрес
+	CustomFieldSmartAddress CustomFieldType = "smart_address" // Адрес
+	CustomFieldBirthday     CustomFieldType = "birthday"      // День рождения
+	CustomFieldLegalEntity  CustomFieldType = "legal_entity"  // Юр. лицо
+	CustomFieldDateTime     CustomFieldType = "date_time"     // Дата и время
+	CustomFieldPrice        CustomFieldType = "price"         // Цена
+	CustomFieldCategory     CustomFieldType = "category"      // Категория
+	CustomFieldItems        CustomFieldType = "items"         // Предметы
+	CustomFieldTrackingData CustomFieldType = "tracking_data" // Отслеживаемые данные
+	CustomFieldLinkedEntity CustomFieldType = "linked_entity" // Связь с другим элементом
+	CustomFieldChainedList  CustomFieldType = "chained_list"  // Каталоги и списки
+	CustomFieldMonetary     CustomFieldType = "monetary"      // Денежное
+	CustomFieldFile         CustomFieldType = "file"          // Файл
+	CustomFieldPayer        CustomFieldType = "payer"         // Плательщик
+	CustomFieldSupplier     CustomFieldType = "supplier"      // Поставщик
+	CustomFieldMultitext    CustomFieldType = "multitext"     // Телефон, Email
+)
+
 type CustomField struct {
-	FieldId   int     `json:"field_id"`
-	FieldName string  `json:"field_name"`
-	FieldCode *string `json:"field_code"`
-	FieldType string  `json:"field_type"`
-	Values    []struct {
-		Value    string `json:"value"`
-		EnumId   int    `json:"enum_id,omitempty"`
-		EnumCode string `json:"enum_code,omitempty"`
-	} `json:"values"`
+	FieldId   int                `json:"field_id,omitempty"`
+	FieldName string             `json:"field_name,omitempty"`
+	FieldCode *string            `json:"field_code,omitempty"`
+	FieldType string             `json:"field_type,omitempty"`
+	Values    []CustomFieldValue `json:"values"`
+}
+
+type GetCustomFieldsQueryParams struct {
+	Page  int `url:"page,omitempty"`
+	Limit int `url:"limit,omitempty"`
+}
+
+type customFieldDef struct {
+	Id               int                   `json:"id,omitempty"`            // ID поля
+	Name             string                `json:"name,omitempty"`          // Название поля
+	Code             string                `json:"code,omitempty"`          // Код поля, по-которому можно обновлять значение в сущности, без передачи ID поля
+	Sort             int                   `json:"sort,omitempty"`          // Сортировка поля
+	Type             CustomFieldType       `json:"type,omitempty"`          // Тип поля
+	EntityType       CustomFieldEntityType `json:"entity_type,omitempty"`   // Тип сущности
+	IsComputed       bool                  `json:"is_computed,omitempty"`   // Является ли поле вычисляемым
+	IsPredefined     bool                  `json:"is_predefined,omitempty"` // Является ли поле предустановленным
+	IsDeletable      bool                  `json:"is_deletable,omitempty"`  // Доступно ли поле для удаления
+	IsVisible        bool                  `json:"is_visible,omitempty"`    // Отображается ли поле в интерфейсе списка
+	IsRequired       bool                  `json:"is_required,omitempty"`   // Обязательно ли поле для заполнения при создании элемента списка
+	IsApiOnly        bool                  `json:"is_api_only,omitempty"`   // Доступно ли поле для редактирования только через API
+	GroupId          string                `json:"group_id,omitempty"`      // ID группы полей, в которой состоит данное поле
+	CatalogId        int                   `json:"catalog_id,omitempty"`    // ID списка для полей типа catalog
+	Remind           string                `json:"remind,omitempty"`        // Когда напоминать о дне рождения (never, day, week, month)
+	Currency         string                `json:"currency,omitempty"`      // Валюта поля типа monetary
+	Settings         interface{}           `json:"settings,omitempty"`      // Настройки поля
+	Enums            []customFieldEnum     `json:"enums,omitempty"`         // Доступные значения для полей списка и мультисписка
+	RequiredStatuses []struct {
+		PipelineId int `json:"pipeline_id"`
+		StatusId   int `json:"status_id"`
+	} `json:"required_statuses,omitempty"` // Обязательные поля для смены этапа
+	AccountId int    `json:"account_id,omitempty"`
+	RequestId string `json:"request_id,omitempty"` // Поле, которое вернется вам в ответе без изменений и не будет сохранено
+	Links     struct {
+		Self struct {
+			Href string `json:"href,omitempty"`
+		} `json:"self,omitempty"`
+	} `json:"_links,omitempty"`
+}
+
+type customFieldEnum struct {
+	Id    int    `json:"id,omitempty"`
+	Value string `json:"value"`
+	Sort  int    `json:"sort,omitempty"`
+	Code  string `json:"code,omitempty"`
+}
+
+type allCustomFields struct {
+	Page     int   `json:"_page"`
+	Links    links `json:"_links"`
+	Embedded struct {
+		CustomFields []*customFieldDef `json:"custom_fields"`
+	} `json:"_embedded"`
+}
+
+type customFieldGroup struct {
+	Id           string                `json:"id,omitempty"`          // ID группы полей
+	Name         string                `json:"name,omitempty"`        // Название группы полей
+	Sort         int                   `json:"sort,omitempty"`        // Сортировка группы полей
+	EntityType   CustomFieldEntityType `json:"entity_type,omitempty"` // Тип сущности
+	IsPredefined bool                  `json:"is_predefined,omitempty"`
+	Fields       []int                 `json:"fields,omitempty"`     // ID полей, входящих в группу
+	RequestId    string                `json:"request_id,omitempty"` // Поле, которое вернется вам в ответе без изменений и не будет сохранено
+}
+
+type allCustomFieldGroups struct {
+	Page     int   `json:"_page"`
+	Links    links `json:"_links"`
+	Embedded struct {
+		CustomFieldGroups []*customFieldGroup `json:"custom_field_groups"`
+	} `json:"_embedded"`
+}
+
+// CustomFieldsForCatalog Возвращает тип сущности для полей списка (каталога)
+func CustomFieldsForCatalog(catalogId int) CustomFieldEntityType {
+	return CustomFieldEntityType(fmt.Sprintf("catalogs/%d", catalogId))
+}
+
+func (c Cf) New() *customFieldDef {
+	return &customFieldDef{}
+}
+
+// All Метод позволяет получить список полей сущности в аккаунте.
+func (c Cf) All(entity CustomFieldEntityType) (CustomFieldDefs, error) {
+	var fields CustomFieldDefs
+
+	params := GetCustomFieldsQueryParams{
+		Limit: 250,
+	}
+	path := fmt.Sprintf("/api/v4/%s/custom_fields", entity)
+
+	for {
+		var tmpFields allCustomFields
+
+		err := c.api().request(requestOpts{
+			Method:        http.MethodGet,
+			Path:          path,
+			URLParameters: &params,
+			Ret:           &tmpFields,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ошибка обработки запроса %s: %w", path, err)
+		}
+
+		fields = append(fields, tmpFields.Embedded.CustomFields...)
+
+		if len(tmpFields.Links.Next.Href) > 0 {
+			params.Page = tmpFields.Page + 1
+		} else {
+			break
+		}
+	}
+
+	return fields, nil
+}
+
+// ByID Метод позволяет получить поле сущности по его ID.
+func (c Cf) ByID(entity CustomFieldEntityType, id int) (*customFieldDef, error) {
+	ret := customFieldDef{}
+
+	return &ret, c.api().request(requestOpts{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("/api/v4/%s/custom_fields/%d", entity, id),
+		Ret:    &ret,
+	})
+}
+
+// Create Метод позволяет создавать дополнительные поля пакетно.
+func (c Cf) Create(entity CustomFieldEntityType, fields CustomFieldDefs) (*allCustomFields, error) {
+	ret := allCustomFields{}
+
+	return &ret, c.api().request(requestOpts{
+		Method:         http.MethodPost,
+		Path:           fmt.Sprintf("/api/v4/%s/custom_fields", entity),
+		DataParameters: &fields,
+		Ret:            &ret,
+	})
+}
+
+// Update Метод позволяет редактировать дополнительные поля пакетно.
+// При передаче enums значения, не указанные в запросе, будут удалены.
+func (c Cf) Update(entity CustomFieldEntityType, fields CustomFieldDefs) (*allCustomFields, error) {
+	ret := allCustomFields{}
+
+	return &ret, c.api().request(requestOpts{
+		Method:         http.MethodPatch,
+		Path:           fmt.Sprintf("/api/v4/%s/custom_fields", entity),
+		DataParameters: &fields,
+		Ret:            &ret,
+	})
+}
+
+// Delete Метод позволяет удалить дополнительное поле. Предустановленные поля удалить нельзя.
+func (c Cf) Delete(entity CustomFieldEntityType, id int) error {
+	return c.api().request(requestOpts{
+		Method: http.MethodDelete,
+		Path:   fmt.Sprintf("/api/v4/%s/custom_fields/%d", entity, id),
+	})
+}
+
+// Groups Метод позволяет получить список групп полей сущности.
+func (c Cf) Groups(entity CustomFieldEntityType) (CustomFieldGroups, error) {
+	var groups CustomFieldGroups
+
+	params := GetCustomFieldsQueryParams{
+		Limit: 250,
+	}
+	path := fmt.Sprintf("/api/v4/%s/custom_fields/groups", entity)
+
+	for {
+		var tmpGroups allCustomFieldGroups
+
+		err := c.api().request(requestOpts{
+			Method:        http.MethodGet,
+			Path:          path,
+			URLParameters: &params,
+			Ret:           &tmpGroups,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ошибка обработки запроса %s: %w", path, err)
+		}
+
+		groups = append(groups, tmpGroups.Embedded.CustomFieldGroups...)
+
+		if len(tmpGroups.Links.Next.Href) > 0 {
+			params.Page = tmpGroups.Page + 1
+		} else {
+			break
+		}
+	}
+
+	return groups, nil
+}
+
+// CreateGroups Метод позволяет создавать группы полей пакетно.
+func (c Cf) CreateGroups(entity CustomFieldEntityType, groups CustomFieldGroups) (*allCustomFieldGroups, error) {
+	ret := allCustomFieldGroups{}
+
+	return &ret, c.api().request(requestOpts{
+		Method:         http.MethodPost,
+		Path:           fmt.Sprintf("/api/v4/%s/custom_fields/groups", entity),
+		DataParameters: &groups,
+		Ret:            &ret,
+	})
+}
+
+// UpdateGroup Метод позволяет изменить название, сортировку и состав группы полей.
+func (c Cf) UpdateGroup(entity CustomFieldEntityType, group *customFieldGroup) (*customFieldGroup, error) {
+	ret := customFieldGroup{}
+
+	return &ret, c.api().request(requestOpts{
+		Method:         http.MethodPatch,
+		Path:           fmt.Sprintf("/api/v4/%s/custom_fields/groups/%s", entity, group.Id),
+		DataParameters: &group,
+		Ret:            &ret,
+	})
+}
+
+// DeleteGroup Метод позволяет удалить группу полей. Поля группы переносятся в основную группу.
+func (c Cf) DeleteGroup(entity CustomFieldEntityType, id string) error {
+	return c.api().request(requestOpts{
+		Method: http.MethodDelete,
+		Path:   fmt.Sprintf("/api/v4/%s/custom_fields/groups/%s", entity, id),
+	})
+}
+
+// CustomFieldRegistry Справочник полей сущности, позволяющий находить поле по коду или названию
+type CustomFieldRegistry struct {
+	service
+
+	mu     sync.RWMutex
+	entity CustomFieldEntityType
+	byId   map[int]*customFieldDef
+	byCode map[string]*customFieldDef
+	byName map[string]*customFieldDef
+}
+
+// Registry Загружает поля сущности и возвращает справочник по ним.
+func (c Cf) Registry(entity CustomFieldEntityType) (*CustomFieldRegistry, error) {
+	r := &CustomFieldRegistry{service: c.service, entity: entity}
+
+	return r, r.Reload()
+}
+
+// Reload Перезагружает справочник полей из API.
+func (r *CustomFieldRegistry) Reload() error {
+	fields, err := Cf{r.service}.All(r.entity)
+	if err != nil {
+		return err
+	}
+
+	r.Load(fields)
+
+	return nil
+}
+
+// Load Заполняет справочник переданными определениями полей без запроса к API.
+func (r *CustomFieldRegistry) Load(fields CustomFieldDefs) {
+	byId := make(map[int]*customFieldDef, len(fields))
+	byCode := make(map[string]*customFieldDef, len(fields))
+	byName := make(map[string]*customFieldDef, len(fields))
+
+	for _, f := range fields {
+		byId[f.Id] = f
+		if f.Code != "" {
+			byCode[strings.ToUpper(f.Code)] = f
+		}
+		byName[strings.ToLower(f.Name)] = f
+	}
+
+	r.mu.Lock()
+	r.byId, r.byCode, r.byName = byId, byCode, byName
+	r.mu.Unlock()
+}
+
+// ByID Возвращает определение поля по ID
+func (r *CustomFieldRegistry) ByID(id int) (*customFieldDef, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	f, ok := r.byId[id]
+
+	return f, ok
+}
+
+// Lookup Возвращает определение поля по коду (PHONE, EMAIL, ...) или, если код не найден, по названию
+func (r *CustomFieldRegistry) Lookup(key string) (*customFieldDef, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if f, ok := r.byCode[strings.ToUpper(key)]; ok {
+		return f, true
+	}
+
+	f, ok := r.byName[strings.ToLower(key)]
+
+	return f, ok
+}
+
+// ID Возвращает ID поля по коду или названию
+func (r *CustomFieldRegistry) ID(key string) (int, bool) {
+	f, ok := r.Lookup(key)
+	if !ok {
+		return 0, false
+	}
+
+	return f.Id, true
+}
+
+// Field Возвращает заготовку значения поля с заполненными ID, кодом и типом
+// для записи в custom_fields_values сущности.
+func (r *CustomFieldRegistry) Field(key string) (CustomField, error) {
+	f, ok := r.Lookup(key)
+	if !ok {
+		return CustomField{}, fmt.Errorf("поле %q не найдено среди полей %s", key, r.entity)
+	}
+
+	cf := CustomField{
+		FieldId:   f.Id,
+		FieldName: f.Name,
+		FieldType: string(f.Type),
+	}
+	if f.Code != "" {
+		code := f.Code
+		cf.FieldCode = &code
+	}
+
+	return cf, nil
+}
+
+// EnumID Возвращает ID значения списка поля по его тексту или коду
+func (r *CustomFieldRegistry) EnumID(key string, value string) (int, error) {
+	f, ok := r.Lookup(key)
+	if !ok {
+		return 0, fmt.Errorf("поле %q не найдено среди полей %s", key, r.entity)
+	}
+
+	for _, e := range f.Enums {
+		if strings.EqualFold(e.Value, value) || (e.Code != "" && strings.EqualFold(e.Code, value)) {
+			return e.Id, nil
+		}
+	}
+
+	return 0, fmt.Errorf("значение %q не найдено в поле %q", value, key)
 }
@@ -0,0 +1,393 @@
+package amocrm_v4
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CustomFields []CustomField
+
+// CustomFieldValue Значение дополнительного поля.
+// Value содержит string, json.Number, bool или map[string]interface{} в зависимости от типа поля.
+type CustomFieldValue struct {
+	Value    interface{} `json:"value,omitempty"`
+	EnumId   int         `json:"enum_id,omitempty"`
+	EnumCode string      `json:"enum_code,omitempty"`
+}
+
+// LegalEntity Значение поля типа legal_entity (Юр. лицо)
+type LegalEntity struct {
+	Name                      string `json:"name,omitempty"`                         // Название организации
+	EntityType                int    `json:"entity_type,omitempty"`                  // Тип юр. лица: 1 – частное лицо, 2 – юридическое лицо
+	Vat                       string `json:"vat_id,omitempty"`                       // ИНН
+	TaxRegistrationReasonCode string `json:"tax_registration_reason_code,omitempty"` // Код причины постановки на учет
+	Address                   string `json:"address,omitempty"`                      // Адрес
+	Kpp                       string `json:"kpp,omitempty"`                          // КПП
+	ExternalUid               string `json:"external_uid,omitempty"`                 // Внешний ID
+}
+
+// LinkedEntity Значение поля типа linked_entity (Связь с другим элементом)
+type LinkedEntity struct {
+	Name       string `json:"name,omitempty"`
+	EntityId   int    `json:"entity_id,omitempty"`
+	EntityType string `json:"entity_type,omitempty"`
+	CatalogId  int    `json:"catalog_id,omitempty"`
+}
+
+// ChainedListItem Значение поля типа chained_list (Каталоги и списки)
+type ChainedListItem struct {
+	CatalogId        int    `json:"catalog_id"`
+	CatalogElementId int    `json:"catalog_element_id"`
+	Name             string `json:"name,omitempty"`
+}
+
+// FileValue Значение поля типа file
+type FileValue struct {
+	FileUuid    string `json:"file_uuid"`
+	VersionUuid string `json:"version_uuid"`
+	FileName    string `json:"file_name,omitempty"`
+	FileSize    int    `json:"file_size,omitempty"`
+}
+
+// TextValue Значение для полей text, textarea, url, streetaddress, tracking_data
+func TextValue(value string) CustomFieldValue {
+	return CustomFieldValue{Value: value}
+}
+
+// NumericValue Значение для полей numeric, price, monetary
+func NumericValue(value float64) CustomFieldValue {
+	return CustomFieldValue{Value: json.Number(strconv.FormatFloat(value, 'f', -1, 64))}
+}
+
+// CheckboxValue Значение для поля checkbox
+func CheckboxValue(value bool) CustomFieldValue {
+	return CustomFieldValue{Value: value}
+}
+
+// DateValue Значение для полей date, date_time, birthday. Передается в Unix Timestamp
+func DateValue(value time.Time) CustomFieldValue {
+	return CustomFieldValue{Value: json.Number(strconv.FormatInt(value.Unix(), 10))}
+}
+
+// EnumValue Значение для полей select, radiobutton, multiselect по ID значения списка
+func EnumValue(enumId int) CustomFieldValue {
+	return CustomFieldValue{EnumId: enumId}
+}
+
+// EnumTextValue Значение для полей select, radiobutton, multiselect по тексту значения списка
+func EnumTextValue(value string) CustomFieldValue {
+	return CustomFieldValue{Value: value}
+}
+
+// MultiselectValues Значения для поля multiselect по ID значений списка
+func MultiselectValues(enumIds ...int) []CustomFieldValue {
+	values := make([]CustomFieldValue, 0, len(enumIds))
+	for _, id := range enumIds {
+		values = append(values, EnumValue(id))
+	}
+
+	return values
+}
+
+// MultitextValue Значение для поля multitext (Телефон, Email). enumCode – WORK, WORKDD, MOB, FAX, HOME, OTHER, PRIV
+func MultitextValue(value string, enumCode string) CustomFieldValue {
+	return CustomFieldValue{Value: value, EnumCode: enumCode}
+}
+
+// SmartAddressValue Значение для поля smart_address. enumCode – address_line_1, address_line_2, city, state, zip, country
+func SmartAddressValue(value string, enumCode string) CustomFieldValue {
+	return CustomFieldValue{Value: value, EnumCode: enumCode}
+}
+
+// LegalEntityValue Значение для поля legal_entity
+func LegalEntityValue(value LegalEntity) CustomFieldValue {
+	return CustomFieldValue{Value: value}
+}
+
+// LinkedEntityValue Значение для поля linked_entity
+func LinkedEntityValue(value LinkedEntity) CustomFieldValue {
+	return CustomFieldValue{Value: value}
+}
+
+// ChainedListValue Значение для поля chained_list
+func ChainedListValue(value ChainedListItem) CustomFieldValue {
+	return CustomFieldValue{Value: value}
+}
+
+// FileFieldValue Значение для поля file
+func FileFieldValue(value FileValue) CustomFieldValue {
+	return CustomFieldValue{Value: value}
+}
+
+func (v *CustomFieldValue) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Value    json.RawMessage `json:"value"`
+		EnumId   int             `json:"enum_id"`
+		EnumCode string          `json:"enum_code"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	v.EnumId = raw.EnumId
+	v.EnumCode = raw.EnumCode
+	v.Value = nil
+
+	if len(raw.Value) == 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw.Value))
+	dec.UseNumber()
+
+	return dec.Decode(&v.Value)
+}
+
+// String Возвращает значение в виде строки
+func (v CustomFieldValue) String() string {
+	switch val := v.Value.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		if val {
+			return "1"
+		}
+		return "0"
+	default:
+		b, _ := json.Marshal(val)
+		return string(b)
+	}
+}
+
+// Float Возвращает значение полей numeric, price, monetary
+func (v CustomFieldValue) Float() (float64, error) {
+	s := strings.TrimSpace(v.String())
+	if s == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+}
+
+// Int Возвращает целочисленное значение поля
+func (v CustomFieldValue) Int() (int, error) {
+	f, err := v.Float()
+
+	return int(f), err
+}
+
+// Bool Возвращает значение поля checkbox
+func (v CustomFieldValue) Bool() bool {
+	switch val := v.Value.(type) {
+	case bool:
+		return val
+	case json.Number:
+		return val.String() != "0"
+	case string:
+		b, err := strconv.ParseBool(val)
+		return err == nil && b
+	}
+
+	return false
+}
+
+// Time Возвращает значение полей date, date_time, birthday
+func (v CustomFieldValue) Time() (time.Time, error) {
+	s := v.String()
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if ts, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
+
+// Decode Декодирует составное значение (legal_entity, linked_entity, chained_list, file и т.д.) в переданную структуру
+func (v CustomFieldValue) Decode(out interface{}) error {
+	b, err := json.Marshal(v.Value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, out)
+}
+
+// LegalEntity Возвращает значение поля legal_entity
+func (v CustomFieldValue) LegalEntity() (*LegalEntity, error) {
+	ret := LegalEntity{}
+
+	return &ret, v.Decode(&ret)
+}
+
+// First Возвращает первое значение поля. Метод безопасен для nil.
+func (cf *CustomField) First() CustomFieldValue {
+	if cf == nil || len(cf.Values) == 0 {
+		return CustomFieldValue{}
+	}
+
+	return cf.Values[0]
+}
+
+// String Возвращает первое значение поля в виде строки
+func (cf *CustomField) String() string {
+	return cf.First().String()
+}
+
+// Strings Возвращает все значения поля в виде строк (multitext, multiselect)
+func (cf *CustomField) Strings() []string {
+	if cf == nil {
+		return nil
+	}
+
+	ret := make([]string, 0, len(cf.Values))
+	for _, v := range cf.Values {
+		ret = append(ret, v.String())
+	}
+
+	return ret
+}
+
+// Float Возвращает первое значение поля в виде числа
+func (cf *CustomField) Float() (float64, error) {
+	return cf.First().Float()
+}
+
+// Int Возвращает первое значение поля в виде целого числа
+func (cf *CustomField) Int() (int, error) {
+	return cf.First().Int()
+}
+
+// Bool Возвращает значение поля checkbox
+func (cf *CustomField) Bool() bool {
+	return cf.First().Bool()
+}
+
+// Time Возвращает значение поля даты
+func (cf *CustomField) Time() (time.Time, error) {
+	return cf.First().Time()
+}
+
+// EnumIds Возвращает ID выбранных значений списка (select, multiselect, radiobutton)
+func (cf *CustomField) EnumIds() []int {
+	if cf == nil {
+		return nil
+	}
+
+	ret := make([]int, 0, len(cf.Values))
+	for _, v := range cf.Values {
+		if v.EnumId != 0 {
+			ret = append(ret, v.EnumId)
+		}
+	}
+
+	return ret
+}
+
+// ByID Возвращает поле по ID или nil, если поле не заполнено
+func (f CustomFields) ByID(id int) *CustomField {
+	for i := range f {
+		if f[i].FieldId == id {
+			return &f[i]
+		}
+	}
+
+	return nil
+}
+
+// ByCode Возвращает поле по коду (PHONE, EMAIL, ...) или nil, если поле не заполнено
+func (f CustomFields) ByCode(code string) *CustomField {
+	for i := range f {
+		if f[i].FieldCode != nil && strings.EqualFold(*f[i].FieldCode, code) {
+			return &f[i]
+		}
+	}
+
+	return nil
+}
+
+// Set Заменяет значение поля с тем же ID (или кодом) либо добавляет его
+func (f *CustomFields) Set(field CustomField) {
+	for i, cf := range *f {
+		if (field.FieldId != 0 && cf.FieldId == field.FieldId) ||
+			(field.FieldId == 0 && field.FieldCode != nil && cf.FieldCode != nil && strings.EqualFold(*cf.FieldCode, *field.FieldCode)) {
+			(*f)[i] = field
+			return
+		}
+	}
+
+	*f = append(*f, field)
+}
+
+// FieldByID Возвращает дополнительное поле сделки по ID
+func (l *lead) FieldByID(id int) *CustomField {
+	return l.CustomFieldsValues.ByID(id)
+}
+
+// FieldByCode Возвращает дополнительное поле сделки по коду
+func (l *lead) FieldByCode(code string) *CustomField {
+	return l.CustomFieldsValues.ByCode(code)
+}
+
+// FieldByID Возвращает дополнительное поле контакта по ID
+func (ct *contact) FieldByID(id int) *CustomField {
+	return ct.CustomFieldsValues.ByID(id)
+}
+
+// FieldByCode Возвращает дополнительное поле контакта по коду
+func (ct *contact) FieldByCode(code string) *CustomField {
+	return ct.CustomFieldsValues.ByCode(code)
+}
+
+// FieldByID Возвращает дополнительное поле элемента списка по ID
+func (e *element) FieldByID(id int) *CustomField {
+	return e.CustomFieldsValues.ByID(id)
+}
+
+// FieldByCode Возвращает дополнительное поле элемента списка по коду
+func (e *element) FieldByCode(code string) *CustomField {
+	return e.CustomFieldsValues.ByCode(code)
+}
+
+// Value Возвращает значение поля для записи в custom_fields_values сущности
+func (r *CustomFieldRegistry) Value(key string, values ...CustomFieldValue) (CustomField, error) {
+	cf, err := r.Field(key)
+	if err != nil {
+		return cf, err
+	}
+
+	cf.Values = values
+
+	return cf, cf.validate()
+}
+
+// validate Проверяет, что значение не используется с полем неподходящего типа
+func (cf CustomField) validate() error {
+	switch CustomFieldType(cf.FieldType) {
+	case CustomFieldCheckbox:
+		for _, v := range cf.Values {
+			if _, ok := v.Value.(bool); !ok {
+				return fmt.Errorf("поле %d типа checkbox принимает только CheckboxValue", cf.FieldId)
+			}
+		}
+	case CustomFieldMultitext:
+		for _, v := range cf.Values {
+			if v.EnumCode == "" && v.EnumId == 0 {
+				return fmt.Errorf("для поля %d типа multitext необходимо указать enum_code", cf.FieldId)
+			}
+		}
+	}
+
+	return nil
+}
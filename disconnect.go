@@ -0,0 +1,210 @@
+package amocrm_v4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type DisconnectReason string
+
+const (
+	DisconnectByHook       DisconnectReason = "hook"          // amoCRM вызвал хук отключения интеграции
+	DisconnectTokenRevoked DisconnectReason = "token_revoked" // amoCRM отклонил refresh_token при обновлении
+)
+
+// ErrAccountDisconnected Интеграция отключена в аккаунте, запросы к API не выполняются
+var ErrAccountDisconnected = errors.New("интеграция отключена в аккаунте")
+
+// DisconnectEvent Событие отключения интеграции от аккаунта
+type DisconnectEvent struct {
+	AccountId int // ID аккаунта. 0, если аккаунт неизвестен (клиент NewClient без запроса Account)
+	Reason    DisconnectReason
+	Err       error // Ответ API для DisconnectTokenRevoked
+}
+
+// DisconnectCallback Обработчик отключения интеграции
+type DisconnectCallback func(e DisconnectEvent)
+
+// DisconnectHandlerConfig Параметры обработчика хука отключения интеграции
+type DisconnectHandlerConfig struct {
+	ClientID     string
+	ClientSecret string
+
+	Manager *TokenManager         // Менеджер, из которого будет удален аккаунт
+	Storage *AuthAmoStorageConfig // Хранилище клиента NewClient, из которого будет удалена запись
+
+	// OnDisconnect Вызывается после удаления токенов
+	OnDisconnect DisconnectCallback
+}
+
+type disconnectHandler struct {
+	cfg DisconnectHandlerConfig
+}
+
+// disconnectClaims Данные JWT хука отключения
+type disconnectClaims struct {
+	AccountId  int    `json:"account_id"`
+	ClientUuid string `json:"client_uuid"`
+}
+
+// NewDisconnectHandler Возвращает http.Handler для хука отключения интеграции. Запрос проверяется
+// по JWT, подписанному секретом интеграции, либо по параметру signature (HMAC-SHA256 от client_uuid|account_id).
+func NewDisconnectHandler(cfg DisconnectHandlerConfig) http.Handler {
+	return &disconnectHandler{cfg: cfg}
+}
+
+func (h *disconnectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	accountId, err := h.verify(r)
+	if err != nil {
+		log.Warnf("Отклонен запрос отключения интеграции: %v", err)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	e := DisconnectEvent{AccountId: accountId, Reason: DisconnectByHook}
+
+	if h.cfg.Manager != nil {
+		if err := h.cfg.Manager.disconnect(e, true); err != nil {
+			log.Errorf("Ошибка при удалении токена аккаунта %d: %v", accountId, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if h.cfg.Storage != nil {
+		if err := deleteAuthDataFromDB(h.cfg.Storage); err != nil {
+			log.Errorf("Ошибка при удалении данных об авторизации в АМО: %v", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		if client.storage == h.cfg.Storage {
+			client.disconnect(e)
+		}
+	}
+
+	log.Infof("Интеграция отключена в аккаунте %d", accountId)
+
+	if h.cfg.OnDisconnect != nil {
+		h.cfg.OnDisconnect(e)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// verify Проверяет подпись запроса и возвращает ID аккаунта
+func (h *disconnectHandler) verify(r *http.Request) (int, error) {
+	q := r.URL.Query()
+
+	token := q.Get("jwt")
+	if bearer := bearerToken(r); bearer != "" {
+		token = bearer
+	}
+
+	if token != "" {
+		claims := disconnectClaims{}
+		if err := verifyJWT(token, []byte(h.cfg.ClientSecret), &claims); err != nil {
+			return 0, err
+		}
+		if claims.ClientUuid != "" && claims.ClientUuid != h.cfg.ClientID {
+			return 0, fmt.Errorf("токен выдан для интеграции %s", claims.ClientUuid)
+		}
+		if claims.AccountId == 0 {
+			return 0, errors.New("в токене не указан account_id")
+		}
+
+		return claims.AccountId, nil
+	}
+
+	clientUuid, account, signature := q.Get("client_uuid"), q.Get("account_id"), q.Get("signature")
+	if signature == "" {
+		return 0, errors.New("запрос не подписан")
+	}
+	if clientUuid != h.cfg.ClientID {
+		return 0, fmt.Errorf("запрос для интеграции %s", clientUuid)
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return 0, fmt.Errorf("некорректная подпись: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(h.cfg.ClientSecret))
+	mac.Write([]byte(clientUuid + "|" + account))
+	if !hmac.Equal(expected, mac.Sum(nil)) {
+		return 0, errors.New("подпись не совпадает")
+	}
+
+	accountId, err := strconv.Atoi(account)
+	if err != nil {
+		return 0, fmt.Errorf("некорректный account_id %q", account)
+	}
+
+	return accountId, nil
+}
+
+// IsTokenRevoked Проверяет, что ошибка получения токена через /oauth2/access_token означает отзыв доступа
+// (invalid_grant или отозванный refresh_token). Ошибки остальных методов API, в том числе 401, отзывом не считаются.
+func IsTokenRevoked(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	if !strings.HasSuffix(apiErr.Path, "/oauth2/access_token") {
+		return false
+	}
+
+	body := strings.ToLower(string(apiErr.Body))
+
+	return strings.Contains(body, "invalid_grant") ||
+		(apiErr.StatusCode == http.StatusBadRequest && strings.Contains(body, "revoked"))
+}
+
+// OnDisconnect Регистрирует обработчик, который вызывается, когда amoCRM отзывает доступ интеграции
+// и фоновое обновление токена останавливается.
+func (a *Amo) OnDisconnect(fn DisconnectCallback) {
+	api := a.api()
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	api.onDisconnect = fn
+}
+
+// Disconnected Проверяет, отключена ли интеграция в аккаунте клиента
+func (a *Amo) Disconnected() bool {
+	return a.api().isDisconnected()
+}
+
+// disconnect Помечает аккаунт отключенным и вызывает обработчик отключения
+func (a *authSettings) disconnect(e DisconnectEvent) {
+	a.mu.Lock()
+	already := a.disconnected
+	a.disconnected = true
+	fn := a.onDisconnect
+	a.mu.Unlock()
+
+	if !already && fn != nil {
+		fn(e)
+	}
+}
+
+func (a *authSettings) isDisconnected() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.disconnected
+}
+
+func deleteAuthDataFromDB(storage *AuthAmoStorageConfig) error {
+	return storage.DB.Table(storage.TableName).Where("app_name = ?", storage.AppName).Delete(&AuthorizationData{}).Error
+}
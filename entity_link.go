@@ -0,0 +1,141 @@
+package amocrm_v4
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type (
+	Lnk            struct{ service }
+	LinkEntityType string
+	EntityLinks    []*EntityLink
+)
+
+const (
+	LinkLeads           LinkEntityType = "leads"
+	LinkContacts        LinkEntityType = "contacts"
+	LinkCompanies       LinkEntityType = "companies"
+	LinkCustomers       LinkEntityType = "customers"
+	LinkCatalogElements LinkEntityType = "catalog_elements"
+)
+
+// EntityLink Связь сущности с другой сущностью
+type EntityLink struct {
+	EntityId     int             `json:"entity_id,omitempty"`   // ID основной сущности
+	EntityType   LinkEntityType  `json:"entity_type,omitempty"` // Тип основной сущности
+	ToEntityId   int             `json:"to_entity_id"`          // ID связанной сущности
+	ToEntityType LinkEntityType  `json:"to_entity_type"`        // Тип связанной сущности
+	Metadata     *EntityLinkMeta `json:"metadata,omitempty"`    // Метаданные связи
+}
+
+// EntityLinkMeta Метаданные связи
+type EntityLinkMeta struct {
+	CatalogId int     `json:"catalog_id,omitempty"` // ID списка, обязателен для связи с элементом списка
+	Quantity  float64 `json:"quantity,omitempty"`   // Количество прикрепленных элементов списка
+	PriceId   int     `json:"price_id,omitempty"`   // ID поля типа Цена, которое будет установлено для привязанного элемента в контексте сущности
+	IsMain    bool    `json:"is_main,omitempty"`    // Является ли привязанный контакт главным
+	UpdatedBy int     `json:"updated_by,omitempty"` // ID пользователя, от имени которого осуществляется прикрепление
+}
+
+type allEntityLinks struct {
+	Page     int   `json:"_page"`
+	Links    links `json:"_links"`
+	Embedded struct {
+		Links []*EntityLink `json:"links"`
+	} `json:"_embedded"`
+}
+
+type GetLinksQueryParams struct {
+	Page               int            `url:"page,omitempty"`
+	Limit              int            `url:"limit,omitempty"`
+	FilterByEntityId   []int          `url:"filter[entity_id][],omitempty"`    // Фильтр по ID основной сущности, используется при запросе связей нескольких сущностей
+	FilterByToEntityId int            `url:"filter[to_entity_id],omitempty"`   // Фильтр по ID связанной сущности, передается вместе с filter[to_entity_type]
+	FilterByToEntity   LinkEntityType `url:"filter[to_entity_type],omitempty"` // Фильтр по типу связанной сущности
+	FilterByCatalogId  int            `url:"filter[to_catalog_id],omitempty"`  // Фильтр по ID списка связанных элементов
+}
+
+// NewLink Создает связь сделки с другой сущностью
+func (l *lead) NewLink(toType LinkEntityType, toId int) *EntityLink {
+	return &EntityLink{
+		EntityId:     l.Id,
+		ToEntityId:   toId,
+		ToEntityType: toType,
+	}
+}
+
+// NewLink Создает связь контакта с другой сущностью
+func (ct *contact) NewLink(toType LinkEntityType, toId int) *EntityLink {
+	return &EntityLink{
+		EntityId:     ct.Id,
+		ToEntityId:   toId,
+		ToEntityType: toType,
+	}
+}
+
+// Link Метод позволяет прикреплять сущности к основной сущности пакетно.
+// Связь с элементом списка требует metadata.catalog_id.
+func (lk Lnk) Link(entity LinkEntityType, items EntityLinks) (*allEntityLinks, error) {
+	ret := allEntityLinks{}
+
+	return &ret, lk.api().request(requestOpts{
+		Method:         http.MethodPost,
+		Path:           fmt.Sprintf("/api/v4/%s/link", entity),
+		DataParameters: &items,
+		Ret:            &ret,
+	})
+}
+
+// Unlink Метод позволяет открепить сущности от основной сущности пакетно.
+func (lk Lnk) Unlink(entity LinkEntityType, items EntityLinks) error {
+	return lk.api().request(requestOpts{
+		Method:         http.MethodPost,
+		Path:           fmt.Sprintf("/api/v4/%s/unlink", entity),
+		DataParameters: &items,
+	})
+}
+
+// ByEntity Метод позволяет получить связанные сущности по ID основной сущности.
+func (lk Lnk) ByEntity(entity LinkEntityType, id int, params *GetLinksQueryParams) (EntityLinks, error) {
+	if params == nil {
+		params = &GetLinksQueryParams{}
+	}
+
+	return lk.multiplyRequest(fmt.Sprintf("/api/v4/%s/%d/links", entity, id), params)
+}
+
+// Query Метод позволяет получить связанные сущности для нескольких основных сущностей одного типа.
+func (lk Lnk) Query(entity LinkEntityType, params *GetLinksQueryParams) (EntityLinks, error) {
+	return lk.multiplyRequest(fmt.Sprintf("/api/v4/%s/links", entity), params)
+}
+
+func (lk Lnk) multiplyRequest(path string, params *GetLinksQueryParams) (EntityLinks, error) {
+	var items EntityLinks
+
+	if params.Limit == 0 {
+		params.Limit = 250
+	}
+
+	for {
+		var tmpLinks allEntityLinks
+
+		err := lk.api().request(requestOpts{
+			Method:        http.MethodGet,
+			Path:          path,
+			URLParameters: &params,
+			Ret:           &tmpLinks,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ошибка обработки запроса %s: %w", path, err)
+		}
+
+		items = append(items, tmpLinks.Embedded.Links...)
+
+		if len(tmpLinks.Links.Next.Href) > 0 {
+			params.Page = tmpLinks.Page + 1
+		} else {
+			break
+		}
+	}
+
+	return items, nil
+}
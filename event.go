@@ -0,0 +1,333 @@
+package amocrm_v4
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type (
+	Ev              struct{ service }
+	EventType       string
+	EventEntityType string
+	Events          []*event
+)
+
+const (
+	EventLeadAdded                 EventType = "lead_added"                    // Новая сделка
+	EventLeadDeleted               EventType = "lead_deleted"                  // Сделка удалена
+	EventLeadRestored              EventType = "lead_restored"                 // Сделка восстановлена
+	EventLeadStatusChanged         EventType = "lead_status_changed"           // Изменение этапа продажи
+	EventLeadLinked                EventType = "lead_linked"                   // Прикрепление сделки
+	EventLeadUnlinked              EventType = "lead_unlinked"                 // Открепление сделки
+	EventContactAdded              EventType = "contact_added"                 // Новый контакт
+	EventContactDeleted            EventType = "contact_deleted"               // Контакт удален
+	EventContactRestored           EventType = "contact_restored"              // Контакт восстановлен
+	EventContactLinked             EventType = "contact_linked"                // Прикрепление контакта
+	EventContactUnlinked           EventType = "contact_unlinked"              // Открепление контакта
+	EventCompanyAdded              EventType = "company_added"                 // Новая компания
+	EventCompanyDeleted            EventType = "company_deleted"               // Компания удалена
+	EventCompanyRestored           EventType = "company_restored"              // Компания восстановлена
+	EventCompanyLinked             EventType = "company_linked"                // Прикрепление компании
+	EventCompanyUnlinked           EventType = "company_unlinked"              // Открепление компании
+	EventCustomerAdded             EventType = "customer_added"                // Новый покупатель
+	EventCustomerDeleted           EventType = "customer_deleted"              // Покупатель удален
+	EventCustomerStatusChanged     EventType = "customer_status_changed"       // Изменение этапа покупателя
+	EventCustomerLinked            EventType = "customer_linked"               // Прикрепление покупателя
+	EventCustomerUnlinked          EventType = "customer_unlinked"             // Открепление покупателя
+	EventTaskAdded                 EventType = "task_added"                    // Новая задача
+	EventTaskDeleted               EventType = "task_deleted"                  // Задача удалена
+	EventTaskCompleted             EventType = "task_completed"                // Завершение задачи
+	EventTaskTypeChanged           EventType = "task_type_changed"             // Изменение типа задачи
+	EventTaskTextChanged           EventType = "task_text_changed"             // Изменение текста задачи
+	EventTaskDeadlineChanged       EventType = "task_deadline_changed"         // Изменение даты исполнения задачи
+	EventTaskResultAdded           EventType = "task_result_added"             // Результат по задаче
+	EventIncomingCall              EventType = "incoming_call"                 // Входящий звонок
+	EventOutgoingCall              EventType = "outgoing_call"                 // Исходящий звонок
+	EventIncomingChatMessage       EventType = "incoming_chat_message"         // Входящее сообщение
+	EventOutgoingChatMessage       EventType = "outgoing_chat_message"         // Исходящее сообщение
+	EventIncomingSms               EventType = "incoming_sms"                  // Входящее SMS
+	EventOutgoingSms               EventType = "outgoing_sms"                  // Исходящее SMS
+	EventEntityTagAdded            EventType = "entity_tag_added"              // Теги добавлены
+	EventEntityTagDeleted          EventType = "entity_tag_deleted"            // Теги убраны
+	EventEntityLinked              EventType = "entity_linked"                 // Прикрепление
+	EventEntityUnlinked            EventType = "entity_unlinked"               // Открепление
+	EventSaleFieldChanged          EventType = "sale_field_changed"            // Изменение поля «Бюджет»
+	EventNameFieldChanged          EventType = "name_field_changed"            // Изменение поля «Название»
+	EventLtvFieldChanged           EventType = "ltv_field_changed"             // Сумма покупок
+	EventEntityResponsibleChanged  EventType = "entity_responsible_changed"    // Ответственный изменен
+	EventRobotReplied              EventType = "robot_replied"                 // Ответ робота
+	EventIntentIdentified          EventType = "intent_identified"             // Тема вопроса определена
+	EventNpsRateAdded              EventType = "nps_rate_added"                // Новая оценка NPS
+	EventLinkFollowed              EventType = "link_followed"                 // Переход по ссылке
+	EventTransactionAdded          EventType = "transaction_added"             // Добавлена покупка
+	EventCommonNoteAdded           EventType = "common_note_added"             // Новое примечание
+	EventCommonNoteDeleted         EventType = "common_note_deleted"           // Примечание удалено
+	EventAttachmentNoteAdded       EventType = "attachment_note_added"         // Добавлен новый файл
+	EventTargetingInNoteAdded      EventType = "targeting_in_note_added"       // Добавление в ретаргетинг
+	EventTargetingOutNoteAdded     EventType = "targeting_out_note_added"      // Удаление из ретаргетинга
+	EventGeoNoteAdded              EventType = "geo_note_added"                // Новое примечание с гео-меткой
+	EventServiceNoteAdded          EventType = "service_note_added"            // Новое системное примечание
+	EventSiteVisitNoteAdded        EventType = "site_visit_note_added"         // Заход на сайт
+	EventMessageToCashierNoteAdded EventType = "message_to_cashier_note_added" // Новое примечание о сообщении кассиру
+	EventKeyActionCompleted        EventType = "key_action_completed"          // Ключевое действие
+	EventEntityMerged              EventType = "entity_merged"                 // Выполнено объединение
+)
+
+const (
+	EventForLead     EventEntityType = "lead"
+	EventForContact  EventEntityType = "contact"
+	EventForCompany  EventEntityType = "company"
+	EventForCustomer EventEntityType = "customer"
+	EventForTask     EventEntityType = "task"
+)
+
+// EventCustomFieldValueChanged Возвращает тип события изменения значения дополнительного поля
+func EventCustomFieldValueChanged(fieldId int) EventType {
+	return EventType(fmt.Sprintf("custom_field_%d_value_changed", fieldId))
+}
+
+// EventForCatalog Возвращает тип сущности для событий элементов списка
+func EventForCatalog(catalogId int) EventEntityType {
+	return EventEntityType(fmt.Sprintf("catalog_%d", catalogId))
+}
+
+type event struct {
+	Id          string          `json:"id"`          // ID события
+	Type        EventType       `json:"type"`        // Тип события
+	EntityId    int             `json:"entity_id"`   // ID сущности события
+	EntityType  EventEntityType `json:"entity_type"` // Сущность события
+	CreatedBy   int             `json:"created_by"`  // ID пользователя, создавшего событие
+	CreatedAt   int             `json:"created_at"`  // Дата создания события, передается в Unix Timestamp
+	ValueAfter  []EventValue    `json:"value_after"` // Массив с изменениями по событию
+	ValueBefore []EventValue    `json:"value_before"`
+	AccountId   int             `json:"account_id"`
+	Links       links           `json:"_links"`
+	Embedded    struct {
+		Entity struct {
+			Id    int   `json:"id"`
+			Links links `json:"_links"`
+		} `json:"entity"`
+	} `json:"_embedded"`
+}
+
+// EventValue Значение до или после события. Заполнено только поле, соответствующее типу события.
+type EventValue struct {
+	LeadStatus *struct {
+		Id         int `json:"id"`
+		PipelineId int `json:"pipeline_id"`
+	} `json:"lead_status,omitempty"`
+	CustomerStatus *struct {
+		Id int `json:"id"`
+	} `json:"customer_status,omitempty"`
+	ResponsibleUser *struct {
+		Id int `json:"id"`
+	} `json:"responsible_user,omitempty"`
+	CustomFieldValue *struct {
+		FieldId   int    `json:"field_id"`
+		FieldType int    `json:"field_type"`
+		EnumId    int    `json:"enum_id,omitempty"`
+		Text      string `json:"text"`
+	} `json:"custom_field_value,omitempty"`
+	SaleFieldValue *struct {
+		Sale float64 `json:"sale"`
+	} `json:"sale_field_value,omitempty"`
+	NameFieldValue *struct {
+		Name string `json:"name"`
+	} `json:"name_field_value,omitempty"`
+	LtvFieldValue *struct {
+		Ltv float64 `json:"ltv"`
+	} `json:"ltv_field_value,omitempty"`
+	Note *struct {
+		Id int `json:"id"`
+	} `json:"note,omitempty"`
+	Task *struct {
+		Id int `json:"id"`
+	} `json:"task,omitempty"`
+	TaskDeadline *struct {
+		Timestamp int `json:"timestamp"`
+	} `json:"task_deadline,omitempty"`
+	TaskType *struct {
+		Id TaskTypeIdType `json:"id"`
+	} `json:"task_type,omitempty"`
+	Tag *struct {
+		Name string `json:"name"`
+	} `json:"tag,omitempty"`
+	Message *struct {
+		Id string `json:"id"`
+	} `json:"message,omitempty"`
+	Link *struct {
+		Entity struct {
+			Id   int    `json:"id"`
+			Type string `json:"type"`
+		} `json:"entity"`
+	} `json:"link,omitempty"`
+	Transaction *struct {
+		Id int `json:"id"`
+	} `json:"transaction,omitempty"`
+}
+
+// EventStatusFilter Фильтр по статусу сделки или покупателя до или после события
+type EventStatusFilter struct {
+	PipelineId int
+	StatusId   int
+}
+
+// EventValueFilter Фильтр по значению до или после события
+type EventValueFilter struct {
+	LeadsStatuses     []EventStatusFilter // Для событий lead_status_changed
+	CustomersStatuses []EventStatusFilter // Для событий customer_status_changed
+	ResponsibleUserId int                 // Для событий entity_responsible_changed
+	Value             string              // Для событий изменения полей: sale_field_changed, name_field_changed, custom_field_*_value_changed
+}
+
+type GetEventsQueryParams struct {
+	With                  []string          `url:"with,comma,omitempty"`
+	Page                  int               `url:"page,omitempty"`
+	Limit                 int               `url:"limit,omitempty"`                    // Количество возвращаемых сущностей за один запрос (Максимум – 100)
+	FilterById            []string          `url:"filter[id][],omitempty"`             // Фильтр по ID события
+	FilterByCreatedAtFrom int               `url:"filter[created_at][from],omitempty"` // Фильтр по дате создания события (от)
+	FilterByCreatedAtTo   int               `url:"filter[created_at][to],omitempty"`   // Фильтр по дате создания события (до)
+	FilterByCreatedBy     []int             `url:"filter[created_by][],omitempty"`     // Фильтр по пользователям, создавшим событие
+	FilterByEntity        []EventEntityType `url:"filter[entity][],omitempty"`         // Фильтр по типу сущности события
+	FilterByEntityId      []int             `url:"filter[entity_id][],omitempty"`      // Фильтр по ID сущности, требует filter[entity]
+	FilterByType          []EventType       `url:"filter[type],comma,omitempty"`       // Фильтр по типу события
+	FilterByValueBefore   *EventValueFilter `url:"filter[value_before],omitempty"`     // Фильтр по значению до события
+	FilterByValueAfter    *EventValueFilter `url:"filter[value_after],omitempty"`      // Фильтр по значению после события
+}
+
+type allEvents struct {
+	Page     int   `json:"_page"`
+	Links    links `json:"_links"`
+	Embedded struct {
+		Events []*event `json:"events"`
+	} `json:"_embedded"`
+}
+
+// EncodeValues Кодирует фильтр в формат filter[value_before][leads_statuses][0][status_id]=...
+func (f *EventValueFilter) EncodeValues(key string, v *url.Values) error {
+	if f == nil {
+		return nil
+	}
+
+	for i, s := range f.LeadsStatuses {
+		prefix := fmt.Sprintf("%s[leads_statuses][%d]", key, i)
+		v.Set(prefix+"[pipeline_id]", strconv.Itoa(s.PipelineId))
+		v.Set(prefix+"[status_id]", strconv.Itoa(s.StatusId))
+	}
+
+	for i, s := range f.CustomersStatuses {
+		v.Set(fmt.Sprintf("%s[customers_statuses][%d][status_id]", key, i), strconv.Itoa(s.StatusId))
+	}
+
+	if f.ResponsibleUserId != 0 {
+		v.Set(key+"[responsible_user_id]", strconv.Itoa(f.ResponsibleUserId))
+	}
+
+	if f.Value != "" {
+		v.Set(key+"[value]", f.Value)
+	}
+
+	return nil
+}
+
+// EventIterator Постраничный итератор по событиям. Следующая страница запрашивается
+// только после того, как обработаны все события текущей.
+type EventIterator struct {
+	service
+
+	params *GetEventsQueryParams
+	buf    []*event
+	cur    *event
+	done   bool
+	err    error
+}
+
+// Iterate Возвращает итератор по событиям, подходящим под фильтр.
+func (e Ev) Iterate(params *GetEventsQueryParams) *EventIterator {
+	if params == nil {
+		params = &GetEventsQueryParams{}
+	}
+	if params.Limit == 0 {
+		params.Limit = 100
+	}
+
+	return &EventIterator{service: e.service, params: params}
+}
+
+// Next Переходит к следующему событию. Возвращает false, если события закончились или произошла ошибка.
+func (it *EventIterator) Next() bool {
+	for len(it.buf) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+
+		it.fetch()
+	}
+
+	it.cur, it.buf = it.buf[0], it.buf[1:]
+
+	return true
+}
+
+// Event Возвращает текущее событие
+func (it *EventIterator) Event() *event {
+	return it.cur
+}
+
+// Err Возвращает ошибку, прервавшую итерацию
+func (it *EventIterator) Err() error {
+	return it.err
+}
+
+func (it *EventIterator) fetch() {
+	var tmpEvents allEvents
+
+	path := "/api/v4/events"
+	err := it.api().request(requestOpts{
+		Method:        http.MethodGet,
+		Path:          path,
+		URLParameters: it.params,
+		Ret:           &tmpEvents,
+	})
+	if err != nil {
+		it.err = fmt.Errorf("ошибка обработки запроса %s: %w", path, err)
+		return
+	}
+
+	it.buf = tmpEvents.Embedded.Events
+
+	if len(tmpEvents.Links.Next.Href) > 0 {
+		it.params.Page = tmpEvents.Page + 1
+	} else {
+		it.done = true
+	}
+}
+
+// Query Возвращает все события, подходящие под фильтр.
+func (e Ev) Query(params *GetEventsQueryParams) (Events, error) {
+	var events Events
+
+	it := e.Iterate(params)
+	for it.Next() {
+		events = append(events, it.Event())
+	}
+
+	if it.Err() != nil {
+		return nil, it.Err()
+	}
+
+	return events, nil
+}
+
+// ByID Метод позволяет получить событие по ID.
+func (e Ev) ByID(id string) (*event, error) {
+	ret := event{}
+
+	return &ret, e.api().request(requestOpts{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("/api/v4/events/%s", id),
+		Ret:    &ret,
+	})
+}
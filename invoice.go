@@ -0,0 +1,337 @@
+package amocrm_v4
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type (
+	Inv                    struct{ service }
+	InvoiceStatus          string
+	InvoiceDiscountType    string
+	InvoicePayerEntityType string
+)
+
+const (
+	InvoiceCreated       InvoiceStatus = "Создан"
+	InvoicePaid          InvoiceStatus = "Оплачен"
+	InvoicePartiallyPaid InvoiceStatus = "Частично оплачен"
+	InvoiceCanceled      InvoiceStatus = "Отменён"
+
+	InvoiceDiscountAmount     InvoiceDiscountType = "amount"     // Скидка в валюте счета
+	InvoiceDiscountPercentage InvoiceDiscountType = "percentage" // Скидка в процентах
+
+	InvoicePayerContact InvoicePayerEntityType = "contacts"
+	InvoicePayerCompany InvoicePayerEntityType = "companies"
+)
+
+// Коды полей списка счетов
+const (
+	invoiceFieldStatus      = "BILL_STATUS"
+	invoiceFieldPrice       = "BILL_PRICE"
+	invoiceFieldPaymentDate = "BILL_PAYMENT_DATE"
+	invoiceFieldItems       = "ITEMS"
+	invoiceFieldPayer       = "PAYER"
+	invoiceFieldVatType     = "VAT_TYPE"
+)
+
+// InvoiceItem Позиция счета (значение поля ITEMS)
+type InvoiceItem struct {
+	Sku         string           `json:"sku,omitempty"`          // Артикул
+	Description string           `json:"description"`            // Название позиции
+	UnitPrice   float64          `json:"unit_price"`             // Цена за единицу
+	Quantity    float64          `json:"quantity"`               // Количество
+	UnitType    string           `json:"unit_type,omitempty"`    // Единица измерения
+	Discount    *InvoiceDiscount `json:"discount,omitempty"`     // Скидка
+	VatRateId   int              `json:"vat_rate_id,omitempty"`  // ID ставки НДС
+	ExternalUid string           `json:"external_uid,omitempty"` // Внешний ID позиции
+	ProductId   int              `json:"product_id,omitempty"`   // ID товара из списка товаров
+}
+
+// InvoiceDiscount Скидка на позицию счета
+type InvoiceDiscount struct {
+	Type  InvoiceDiscountType `json:"type"`
+	Value float64             `json:"value"`
+}
+
+// InvoicePayer Плательщик по счету (значение поля PAYER)
+type InvoicePayer struct {
+	Name       string                 `json:"name,omitempty"`
+	EntityId   int                    `json:"entity_id,omitempty"`
+	EntityType InvoicePayerEntityType `json:"entity_type,omitempty"`
+}
+
+// Invoice Счет из списка счетов с разобранными полями
+type Invoice struct {
+	Id          int
+	Name        string        // Номер или название счета
+	Status      InvoiceStatus // Статус счета
+	Price       float64       // Итоговая сумма
+	PaymentDate time.Time     // Дата оплаты
+	Items       []InvoiceItem // Позиции счета
+	Payer       *InvoicePayer // Плательщик
+	VatType     string        // Тип НДС
+	InvoiceLink string        // Ссылка на печатную форму счета
+	LeadId      int           // ID сделки, к которой будет прикреплен счет при создании
+	Element     *element      // Исходный элемент списка
+}
+
+// catalog Возвращает список счетов аккаунта
+func (i Inv) catalog() (*catalog, error) {
+	return Ctg{i.service}.ByType(CatalogInvoices)
+}
+
+// All Метод возвращает все счета с печатной ссылкой.
+func (i Inv) All() ([]*Invoice, error) {
+	return i.Query(&GetCatalogElementsQueryParams{})
+}
+
+// Query Метод возвращает счета по параметрам. Ссылка на печатную форму запрашивается всегда.
+func (i Inv) Query(params *GetCatalogElementsQueryParams) ([]*Invoice, error) {
+	ctg, err := i.catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasElementWith(params.With, ElementWithInvoiceLink) {
+		if params.With == "" {
+			params.With = ElementWithInvoiceLink
+		} else {
+			params.With += "," + ElementWithInvoiceLink
+		}
+	}
+
+	elements, err := ctg.QueryElements(params)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := make([]*Invoice, 0, len(elements))
+	for _, e := range elements {
+		inv, err := invoiceFromElement(e)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, inv)
+	}
+
+	return invoices, nil
+}
+
+// ByID Метод возвращает счет по ID.
+func (i Inv) ByID(id int) (*Invoice, error) {
+	ctg, err := i.catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := ctg.ElementByID(id, ElementWithInvoiceLink)
+	if err != nil {
+		return nil, err
+	}
+
+	return invoiceFromElement(e)
+}
+
+// Create Метод создает счета, прикрепляет их к сделкам (если указан LeadId)
+// и возвращает созданные счета со ссылкой на печатную форму.
+func (i Inv) Create(invoices []*Invoice) ([]*Invoice, error) {
+	ctg, err := i.catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	elements := make(Elements, 0, len(invoices))
+	for _, inv := range invoices {
+		elements = append(elements, inv.element(ctg.Id))
+	}
+
+	created, err := ctg.CreateElements(elements)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(created))
+	for _, e := range created {
+		ids = append(ids, e.Id)
+	}
+
+	// ссылка на печатную форму возвращается только при чтении, запрашиваем все созданные счета одним запросом
+	full, err := ctg.QueryElements(&GetCatalogElementsQueryParams{FilterByIDs: ids, With: ElementWithInvoiceLink})
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[int]*element, len(full))
+	for _, e := range full {
+		byId[e.Id] = e
+	}
+
+	var links EntityLinks
+	ret := make([]*Invoice, 0, len(created))
+	for n, e := range created {
+		if n < len(invoices) && invoices[n].LeadId != 0 {
+			links = append(links, &EntityLink{
+				EntityId:     invoices[n].LeadId,
+				ToEntityId:   e.Id,
+				ToEntityType: LinkCatalogElements,
+				Metadata:     &EntityLinkMeta{CatalogId: ctg.Id, Quantity: 1},
+			})
+		}
+
+		if f, ok := byId[e.Id]; ok {
+			e = f
+		}
+
+		inv, err := invoiceFromElement(e)
+		if err != nil {
+			return nil, err
+		}
+		if n < len(invoices) {
+			inv.LeadId = invoices[n].LeadId
+		}
+		ret = append(ret, inv)
+	}
+
+	if len(links) > 0 {
+		if _, err := (Lnk{i.service}).Link(LinkLeads, links); err != nil {
+			return ret, fmt.Errorf("счета созданы, но не прикреплены к сделкам: %v", err)
+		}
+	}
+
+	return ret, nil
+}
+
+// Update Метод обновляет счета пакетно.
+func (i Inv) Update(invoices []*Invoice) error {
+	ctg, err := i.catalog()
+	if err != nil {
+		return err
+	}
+
+	elements := make(Elements, 0, len(invoices))
+	for _, inv := range invoices {
+		if inv.Id == 0 {
+			return fmt.Errorf("не указан ID счета %q", inv.Name)
+		}
+		elements = append(elements, inv.element(ctg.Id))
+	}
+
+	_, err = ctg.UpdateElements(elements)
+
+	return err
+}
+
+// SetStatus Метод меняет статус счета.
+func (i Inv) SetStatus(id int, status InvoiceStatus) error {
+	return i.Update([]*Invoice{{Id: id, Status: status}})
+}
+
+// LinkToLead Метод прикрепляет счет к сделке.
+func (i Inv) LinkToLead(invoiceId int, leadId int) error {
+	ctg, err := i.catalog()
+	if err != nil {
+		return err
+	}
+
+	_, err = Lnk{i.service}.Link(LinkLeads, EntityLinks{{
+		EntityId:     leadId,
+		ToEntityId:   invoiceId,
+		ToEntityType: LinkCatalogElements,
+		Metadata:     &EntityLinkMeta{CatalogId: ctg.Id, Quantity: 1},
+	}})
+
+	return err
+}
+
+// element Переводит счет в элемент списка для записи. Незаполненные поля не передаются.
+func (inv *Invoice) element(catalogId int) *element {
+	e := &element{
+		Id:        inv.Id,
+		CatalogId: catalogId,
+		Name:      inv.Name,
+	}
+
+	set := func(code string, values ...CustomFieldValue) {
+		c := code
+		e.CustomFieldsValues.Set(CustomField{FieldCode: &c, Values: values})
+	}
+
+	if inv.Status != "" {
+		set(invoiceFieldStatus, EnumTextValue(string(inv.Status)))
+	}
+	if inv.Price != 0 {
+		set(invoiceFieldPrice, NumericValue(inv.Price))
+	}
+	if !inv.PaymentDate.IsZero() {
+		set(invoiceFieldPaymentDate, DateValue(inv.PaymentDate))
+	}
+	if len(inv.Items) > 0 {
+		values := make([]CustomFieldValue, 0, len(inv.Items))
+		for _, item := range inv.Items {
+			values = append(values, CustomFieldValue{Value: item})
+		}
+		set(invoiceFieldItems, values...)
+	}
+	if inv.Payer != nil {
+		set(invoiceFieldPayer, CustomFieldValue{Value: inv.Payer})
+	}
+	if inv.VatType != "" {
+		set(invoiceFieldVatType, EnumTextValue(inv.VatType))
+	}
+
+	return e
+}
+
+func invoiceFromElement(e *element) (*Invoice, error) {
+	inv := &Invoice{
+		Id:      e.Id,
+		Name:    e.Name,
+		Status:  InvoiceStatus(e.FieldByCode(invoiceFieldStatus).String()),
+		VatType: e.FieldByCode(invoiceFieldVatType).String(),
+		Element: e,
+	}
+
+	if e.InvoiceLink != nil {
+		inv.InvoiceLink = *e.InvoiceLink
+	}
+
+	var err error
+	if inv.Price, err = e.FieldByCode(invoiceFieldPrice).Float(); err != nil {
+		return nil, fmt.Errorf("ошибка разбора суммы счета %d: %v", e.Id, err)
+	}
+
+	if inv.PaymentDate, err = e.FieldByCode(invoiceFieldPaymentDate).Time(); err != nil {
+		return nil, fmt.Errorf("ошибка разбора даты оплаты счета %d: %v", e.Id, err)
+	}
+
+	if items := e.FieldByCode(invoiceFieldItems); items != nil {
+		for _, v := range items.Values {
+			item := InvoiceItem{}
+			if err := v.Decode(&item); err != nil {
+				return nil, fmt.Errorf("ошибка разбора позиции счета %d: %v", e.Id, err)
+			}
+			inv.Items = append(inv.Items, item)
+		}
+	}
+
+	if payer := e.FieldByCode(invoiceFieldPayer); payer != nil {
+		inv.Payer = &InvoicePayer{}
+		if err := payer.First().Decode(inv.Payer); err != nil {
+			return nil, fmt.Errorf("ошибка разбора плательщика счета %d: %v", e.Id, err)
+		}
+	}
+
+	return inv, nil
+}
+
+func hasElementWith(with ElementWithType, w ElementWithType) bool {
+	for _, v := range strings.Split(string(with), ",") {
+		if ElementWithType(v) == w {
+			return true
+		}
+	}
+
+	return false
+}
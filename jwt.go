@@ -0,0 +1,90 @@
+package amocrm_v4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// jwtLeeway Допустимое расхождение часов при проверке exp и nbf
+const jwtLeeway = 30 * time.Second
+
+// ErrInvalidToken Подпись или срок действия JWT не прошли проверку
+var ErrInvalidToken = errors.New("некорректный токен")
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// jwtRegisteredClaims Стандартные поля JWT, которые проверяются при разборе
+type jwtRegisteredClaims struct {
+	Exp int64 `json:"exp"`
+	Nbf int64 `json:"nbf"`
+	Iat int64 `json:"iat"`
+}
+
+// verifyJWT Проверяет подпись HS256 и сроки действия токена и разбирает его поля в claims.
+// Токены без exp не принимаются, чтобы перехваченный токен нельзя было использовать бессрочно.
+func verifyJWT(token string, secret []byte, claims interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("%w: ожидается три части", ErrInvalidToken)
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("%w: заголовок: %v", ErrInvalidToken, err)
+	}
+
+	header := jwtHeader{}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return fmt.Errorf("%w: заголовок: %v", ErrInvalidToken, err)
+	}
+	if header.Alg != "HS256" {
+		return fmt.Errorf("%w: неподдерживаемый алгоритм %q", ErrInvalidToken, header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("%w: подпись: %v", ErrInvalidToken, err)
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return fmt.Errorf("%w: подпись не совпадает", ErrInvalidToken)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fmt.Errorf("%w: данные: %v", ErrInvalidToken, err)
+	}
+
+	registered := jwtRegisteredClaims{}
+	if err := json.Unmarshal(payload, &registered); err != nil {
+		return fmt.Errorf("%w: данные: %v", ErrInvalidToken, err)
+	}
+
+	now := time.Now()
+	if registered.Exp == 0 {
+		return fmt.Errorf("%w: не указан срок действия", ErrInvalidToken)
+	}
+	if now.After(time.Unix(registered.Exp, 0).Add(jwtLeeway)) {
+		return fmt.Errorf("%w: срок действия истек", ErrInvalidToken)
+	}
+	if registered.Nbf != 0 && now.Add(jwtLeeway).Before(time.Unix(registered.Nbf, 0)) {
+		return fmt.Errorf("%w: токен еще не действует", ErrInvalidToken)
+	}
+
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return fmt.Errorf("%w: данные: %v", ErrInvalidToken, err)
+	}
+
+	return nil
+}
@@ -5,7 +5,7 @@ import (
 	"net/http"
 )
 
-type Ld struct{}
+type Ld struct{ service }
 type LeadWithType string
 
 const (
@@ -42,26 +42,28 @@ type GetLeadsQueryParams struct {
 }
 
 type lead struct {
-	Id                     int           `json:"id,omitempty"`                         //ID сделки
-	Name                   string        `json:"name,omitempty"`                       //Название сделки
-	Price                  int           `json:"price,omitempty"`                      //Бюджет сделки
-	ResponsibleUserId      int           `json:"responsible_user_id,omitempty"`        //ID пользователя, ответственного за сделку
-	GroupId                int           `json:"group_id,omitempty"`                   //ID группы, в которой состоит ответственны пользователь за сделку
-	StatusId               int           `json:"status_id,omitempty"`                  //ID статуса, в который добавляется сделка, по-умолчанию – первый этап главной воронки
-	PipelineId             int           `json:"pipeline_id,omitempty"`                //ID воронки, в которую добавляется сделка
-	LossReasonId           interface{}   `json:"loss_reason_id,omitempty"`             //ID причины отказа
-	SourceId               interface{}   `json:"source_id,omitempty"`                  //Требуется GET параметр with. ID источника сделки
-	CreatedBy              int           `json:"created_by,omitempty"`                 //ID пользователя, создающий сделку
-	UpdatedBy              int           `json:"updated_by,omitempty"`                 //ID пользователя, изменяющий сделку
-	CreatedAt              int           `json:"created_at,omitempty"`                 //Дата создания сделки, передается в Unix Timestamp
-	UpdatedAt              int           `json:"updated_at,omitempty"`                 //Дата изменения сделки, передается в Unix Timestamp
-	ClosedAt               int           `json:"closed_at,omitempty"`                  //Дата закрытия сделки, передается в Unix Timestamp
-	ClosestTaskAt          interface{}   `json:"closest_task_at,omitempty"`            //Дата ближайшей задачи к выполнению, передается в Unix Timestamp
-	IsDeleted              bool          `json:"is_deleted,omitempty"`                 //Удалена ли сделка
-	CustomFieldsValues     []CustomField `json:"custom_fields_values,omitempty"`       //Массив, содержащий информацию по значениям дополнительных полей, заданных для данной сделки
-	Score                  interface{}   `json:"score,omitempty"`                      //Скоринг сделки
-	AccountId              int           `json:"account_id,omitempty"`                 //ID аккаунта, в котором находится сделка
-	IsPriceModifiedByRobot bool          `json:"is_price_modified_by_robot,omitempty"` //Требуется GET параметр with. Изменен ли в последний раз бюджет сделки роботом
+	service
+
+	Id                     int          `json:"id,omitempty"`                         //ID сделки
+	Name                   string       `json:"name,omitempty"`                       //Название сделки
+	Price                  int          `json:"price,omitempty"`                      //Бюджет сделки
+	ResponsibleUserId      int          `json:"responsible_user_id,omitempty"`        //ID пользователя, ответственного за сделку
+	GroupId                int          `json:"group_id,omitempty"`                   //ID группы, в которой состоит ответственны пользователь за сделку
+	StatusId               int          `json:"status_id,omitempty"`                  //ID статуса, в который добавляется сделка, по-умолчанию – первый этап главной воронки
+	PipelineId             int          `json:"pipeline_id,omitempty"`                //ID воронки, в которую добавляется сделка
+	LossReasonId           interface{}  `json:"loss_reason_id,omitempty"`             //ID причины отказа
+	SourceId               interface{}  `json:"source_id,omitempty"`                  //Требуется GET параметр with. ID источника сделки
+	CreatedBy              int          `json:"created_by,omitempty"`                 //ID пользователя, создающий сделку
+	UpdatedBy              int          `json:"updated_by,omitempty"`                 //ID пользователя, изменяющий сделку
+	CreatedAt              int          `json:"created_at,omitempty"`                 //Дата создания сделки, передается в Unix Timestamp
+	UpdatedAt              int          `json:"updated_at,omitempty"`                 //Дата изменения сделки, передается в Unix Timestamp
+	ClosedAt               int          `json:"closed_at,omitempty"`                  //Дата закрытия сделки, передается в Unix Timestamp
+	ClosestTaskAt          interface{}  `json:"closest_task_at,omitempty"`            //Дата ближайшей задачи к выполнению, передается в Unix Timestamp
+	IsDeleted              bool         `json:"is_deleted,omitempty"`                 //Удалена ли сделка
+	CustomFieldsValues     CustomFields `json:"custom_fields_values,omitempty"`       //Массив, содержащий информацию по значениям дополнительных полей, заданных для данной сделки
+	Score                  interface{}  `json:"score,omitempty"`                      //Скоринг сделки
+	AccountId              int          `json:"account_id,omitempty"`                 //ID аккаунта, в котором находится сделка
+	IsPriceModifiedByRobot bool         `json:"is_price_modified_by_robot,omitempty"` //Требуется GET параметр with. Изменен ли в последний раз бюджет сделки роботом
 	Embedded               struct {
 		Tags     []Tag `json:"tags,omitempty"`
 		Contacts []struct {
@@ -91,41 +93,58 @@ type allLeads struct {
 	Page     int   `json:"_page"`
 	Links    links `json:"_links"`
 	Embedded struct {
-		Leads []*lead `json:"leads"`
+		Leads Leads `json:"leads"`
 	} `json:"_embedded"`
 }
 
 func (l Ld) New() *lead {
-	return &lead{}
+	return &lead{service: l.service}
 }
 
 func (l *lead) NewTask() *task {
 	return &task{
+		service:    l.service,
 		EntityType: TaskForLead,
 		EntityId:   l.Id,
 	}
 }
 
+func (l *lead) NewNote() *note {
+	return &note{
+		service:    l.service,
+		EntityId:   l.Id,
+		EntityType: NoteEntityTypeLead,
+	}
+}
+
 func (l Ld) Create(leads Leads) (*allLeads, error) {
 	ret := allLeads{}
 
-	return &ret, httpRequest(requestOpts{
+	err := l.api().request(requestOpts{
 		Method:         http.MethodPost,
 		Path:           "/api/v4/leads",
 		DataParameters: &leads,
 		Ret:            &ret,
 	})
+
+	ret.Embedded.Leads.bind(l.service)
+
+	return &ret, err
 }
 
 func (l Ld) Update(leads Leads) (*allLeads, error) {
 	ret := allLeads{}
 
-	return &ret, httpRequest(requestOpts{
+	err := l.api().request(requestOpts{
 		Method:         http.MethodPatch,
 		Path:           "/api/v4/leads",
 		DataParameters: &leads,
 		Ret:            &ret,
 	})
+
+	ret.Embedded.Leads.bind(l.service)
+
+	return &ret, err
 }
 
 func (l Ld) All() ([]*lead, error) {
@@ -155,15 +174,18 @@ func (l Ld) Query(params *GetLeadsQueryParams) ([]*lead, error) {
 func (l Ld) ByID(id int) (*lead, error) {
 	var ld *lead
 
-	err := httpRequest(requestOpts{
+	err := l.api().request(requestOpts{
 		Method: http.MethodGet,
 		Path:   fmt.Sprintf("/api/v4/leads/%d", id),
 		Ret:    &ld,
 	})
-	if err != nil {
+	// на отсутствующую сущность amoCRM отвечает 204 без тела
+	if err != nil || ld == nil {
 		return nil, err
 	}
 
+	ld.service = l.service
+
 	return ld, nil
 }
 
@@ -175,7 +197,7 @@ func (l Ld) multiplyRequest(params *GetLeadsQueryParams) ([]*lead, error) {
 	for {
 		var tmpLeads allLeads
 
-		err := httpRequest(requestOpts{
+		err := l.api().request(requestOpts{
 			Method:        http.MethodGet,
 			Path:          path,
 			URLParameters: &params,
@@ -185,6 +207,7 @@ func (l Ld) multiplyRequest(params *GetLeadsQueryParams) ([]*lead, error) {
 			return nil, err
 		}
 
+		tmpLeads.Embedded.Leads.bind(l.service)
 		leads = append(leads, tmpLeads.Embedded.Leads...)
 
 		if len(tmpLeads.Links.Next.Href) > 0 {
@@ -196,3 +219,9 @@ func (l Ld) multiplyRequest(params *GetLeadsQueryParams) ([]*lead, error) {
 
 	return leads, nil
 }
+
+func (ls Leads) bind(s service) {
+	for _, l := range ls {
+		l.service = s
+	}
+}
@@ -0,0 +1,151 @@
+package amocrm_v4
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type ComplexLeads []*complexLead
+
+// complexLead Сделка с вложенными контактом и компанией для метода /api/v4/leads/complex
+type complexLead struct {
+	lead
+	Embedded complexLeadEmbedded `json:"_embedded"`
+
+	// DuplicateControl Включает или отключает контроль дублей для сделки. Если не задан, действуют настройки аккаунта.
+	DuplicateControl *bool `json:"duplicate_control,omitempty"`
+}
+
+type complexLeadEmbedded struct {
+	Tags      []Tag        `json:"tags,omitempty"`
+	Contacts  []*contact   `json:"contacts,omitempty"`  // Данные контакта. Можно передать только один контакт
+	Companies []*company   `json:"companies,omitempty"` // Данные компании. Можно передать только одну компанию
+	Source    *LeadSource  `json:"source,omitempty"`    // Источник сделки
+	Metadata  *ComplexMeta `json:"metadata,omitempty"`  // Метаданные неразобранного, если сделка должна попасть в неразобранное
+}
+
+// complexContact Данные контакта, передаваемые при комплексном добавлении и создании неразобранного.
+// Незаполненные поля не отправляются.
+type complexContact struct {
+	Id                 int          `json:"id,omitempty"`
+	Name               string       `json:"name,omitempty"`
+	FirstName          string       `json:"first_name,omitempty"`
+	LastName           string       `json:"last_name,omitempty"`
+	ResponsibleUserId  int          `json:"responsible_user_id,omitempty"`
+	CreatedBy          int          `json:"created_by,omitempty"`
+	UpdatedBy          int          `json:"updated_by,omitempty"`
+	CreatedAt          int          `json:"created_at,omitempty"`
+	UpdatedAt          int          `json:"updated_at,omitempty"`
+	CustomFieldsValues CustomFields `json:"custom_fields_values,omitempty"`
+	Embedded           *struct {
+		Tags []Tag `json:"tags,omitempty"`
+	} `json:"_embedded,omitempty"`
+}
+
+// LeadSource Источник сделки
+type LeadSource struct {
+	ExternalId int    `json:"external_id,omitempty"` // Внешний ID источника
+	Type       string `json:"type,omitempty"`        // Тип источника
+}
+
+// ComplexMeta Метаданные для создания сделки в неразобранном через комплексное добавление
+type ComplexMeta struct {
+	Category   string `json:"category,omitempty"`     // Тип неразобранного: forms, sip
+	FormId     string `json:"form_id,omitempty"`      // Идентификатор формы на стороне интеграции
+	FormName   string `json:"form_name,omitempty"`    // Название формы
+	FormPage   string `json:"form_page,omitempty"`    // Страница, на которой установлена форма
+	Ip         string `json:"ip,omitempty"`           // IP адрес, с которого поступила заявка
+	FormSentAt int    `json:"form_sent_at,omitempty"` // Время отправки данных через форму в Unix Timestamp
+	Referer    string `json:"referer,omitempty"`      // Откуда был переход на страницу с формой
+}
+
+// complexLeadResult Результат комплексного добавления одной сделки
+type complexLeadResult struct {
+	Id        int      `json:"id"`                   // ID созданной сделки
+	ContactId int      `json:"contact_id,omitempty"` // ID созданного или найденного контакта
+	CompanyId int      `json:"company_id,omitempty"` // ID созданной или найденной компании
+	RequestId []string `json:"request_id"`           // Переданные request_id сделки
+	Merged    bool     `json:"merged"`               // Была ли сделка склеена контролем дублей с уже существующей
+}
+
+// NewComplex Создает сделку для комплексного добавления вместе с контактом и компанией
+func (l Ld) NewComplex() *complexLead {
+	return &complexLead{}
+}
+
+// WithContact Добавляет к сделке данные контакта
+func (cl *complexLead) WithContact(ct *contact) *complexLead {
+	cl.Embedded.Contacts = []*contact{ct}
+
+	return cl
+}
+
+// MarshalJSON Передает контакты только с заполненными полями
+func (e complexLeadEmbedded) MarshalJSON() ([]byte, error) {
+	type embedded complexLeadEmbedded
+
+	return json.Marshal(struct {
+		embedded
+		Contacts []complexContact `json:"contacts,omitempty"`
+	}{embedded: embedded(e), Contacts: complexContacts(e.Contacts)})
+}
+
+// complexContacts Оставляет в контактах только поля, которые можно передать при создании
+func complexContacts(cts []*contact) []complexContact {
+	contacts := make([]complexContact, 0, len(cts))
+	for _, ct := range cts {
+		c := complexContact{
+			Id:                 ct.Id,
+			Name:               ct.Name,
+			FirstName:          ct.FirstName,
+			LastName:           ct.LastName,
+			ResponsibleUserId:  ct.ResponsibleUserId,
+			CreatedBy:          ct.CreatedBy,
+			UpdatedBy:          ct.UpdatedBy,
+			CreatedAt:          ct.CreatedAt,
+			UpdatedAt:          ct.UpdatedAt,
+			CustomFieldsValues: ct.CustomFieldsValues,
+		}
+		if len(ct.Embedded.Tags) > 0 {
+			c.Embedded = &struct {
+				Tags []Tag `json:"tags,omitempty"`
+			}{Tags: ct.Embedded.Tags}
+		}
+		contacts = append(contacts, c)
+	}
+
+	return contacts
+}
+
+// WithDuplicateControl Включает или отключает контроль дублей при добавлении сделки
+func (cl *complexLead) WithDuplicateControl(enabled bool) *complexLead {
+	cl.DuplicateControl = &enabled
+
+	return cl
+}
+
+// WithCompany Добавляет к сделке данные компании
+func (cl *complexLead) WithCompany(cmp *company) *complexLead {
+	cl.Embedded.Companies = []*company{cmp}
+
+	return cl
+}
+
+// CreateComplex Метод позволяет пакетно добавлять сделки вместе с контактом и компанией за один запрос.
+// Если в аккаунте включен контроль дублей, контакт и компания могут быть найдены среди существующих,
+// а сделка – склеена с уже существующей; такие сделки отмечаются флагом Merged.
+func (l Ld) CreateComplex(leads ComplexLeads) ([]*complexLeadResult, error) {
+	var ret []*complexLeadResult
+
+	err := l.api().request(requestOpts{
+		Method:         http.MethodPost,
+		Path:           "/api/v4/leads/complex",
+		DataParameters: &leads,
+		Ret:            &ret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,206 @@
+package amocrm_v4
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// LeadStatusWon ID статуса «Успешно реализовано», одинаковый во всех воронках
+	LeadStatusWon = 142
+	// LeadStatusLost ID статуса «Закрыто и не реализовано», одинаковый во всех воронках
+	LeadStatusLost = 143
+
+	// leadEventsChunk Количество сделок, события которых запрашиваются за один проход
+	leadEventsChunk = 10
+)
+
+// LeadStage Этап воронки
+type LeadStage struct {
+	PipelineId int
+	StatusId   int
+}
+
+// LeadStatusPeriod Период, который сделка провела в одном статусе
+type LeadStatusPeriod struct {
+	LeadStage
+	From      time.Time // Когда сделка попала в статус. Нулевое значение, если время создания сделки неизвестно
+	To        time.Time // Когда сделка покинула статус. Нулевое значение, если сделка всё ещё в статусе
+	ChangedBy int       // ID пользователя, переместившего сделку в статус. 0 для начального статуса
+}
+
+// StageStats Суммарное время, проведенное сделками в этапе
+type StageStats struct {
+	LeadStage
+	Total time.Duration // Суммарное время всех сделок в этапе
+	Leads int           // Количество сделок, побывавших в этапе
+}
+
+// Duration Возвращает длительность периода. Для текущего статуса считается до now.
+// Если начало периода неизвестно, возвращает 0.
+func (p LeadStatusPeriod) Duration(now time.Time) time.Duration {
+	if p.From.IsZero() {
+		return 0
+	}
+
+	if p.To.IsZero() {
+		return now.Sub(p.From)
+	}
+
+	return p.To.Sub(p.From)
+}
+
+// IsClosed Является ли этап финальным (успешно или не реализовано)
+func (s LeadStage) IsClosed() bool {
+	return s.StatusId == LeadStatusWon || s.StatusId == LeadStatusLost
+}
+
+// Average Возвращает среднее время сделки в этапе
+func (s StageStats) Average() time.Duration {
+	if s.Leads == 0 {
+		return 0
+	}
+
+	return s.Total / time.Duration(s.Leads)
+}
+
+// StatusTimeline Восстанавливает историю статусов сделки по событиям lead_status_changed.
+func (l *lead) StatusTimeline() ([]LeadStatusPeriod, error) {
+	events, err := leadStatusEvents(Ev{l.service}, []int{l.Id})
+	if err != nil {
+		return nil, err
+	}
+
+	return leadStatusTimeline(l, events[l.Id]), nil
+}
+
+// TimeInStages Считает время, проведенное сделками в каждом этапе воронки.
+// Время в текущем статусе считается до now. Финальные статусы (142, 143) и периоды без известного начала не учитываются.
+func (l Ld) TimeInStages(leads Leads, now time.Time) (map[LeadStage]*StageStats, error) {
+	ids := make([]int, 0, len(leads))
+	for _, ld := range leads {
+		ids = append(ids, ld.Id)
+	}
+
+	events, err := leadStatusEvents(Ev{l.service}, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[LeadStage]*StageStats)
+	for _, ld := range leads {
+		visited := make(map[LeadStage]bool)
+
+		for _, p := range leadStatusTimeline(ld, events[ld.Id]) {
+			// время в начальном статусе неизвестно, если у сделки нет даты создания
+			if p.IsClosed() || p.From.IsZero() {
+				continue
+			}
+
+			s, ok := stats[p.LeadStage]
+			if !ok {
+				s = &StageStats{LeadStage: p.LeadStage}
+				stats[p.LeadStage] = s
+			}
+
+			s.Total += p.Duration(now)
+			if !visited[p.LeadStage] {
+				visited[p.LeadStage] = true
+				s.Leads++
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+// leadStatusEvents Возвращает события смены статуса, сгруппированные по ID сделки
+func leadStatusEvents(ev Ev, ids []int) (map[int]Events, error) {
+	ret := make(map[int]Events, len(ids))
+
+	for start := 0; start < len(ids); start += leadEventsChunk {
+		end := start + leadEventsChunk
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		it := ev.Iterate(&GetEventsQueryParams{
+			FilterByEntity:   []EventEntityType{EventForLead},
+			FilterByEntityId: ids[start:end],
+			FilterByType:     []EventType{EventLeadStatusChanged},
+		})
+		for it.Next() {
+			e := it.Event()
+			ret[e.EntityId] = append(ret[e.EntityId], e)
+		}
+
+		if it.Err() != nil {
+			return nil, it.Err()
+		}
+	}
+
+	return ret, nil
+}
+
+// leadStatusTimeline Строит историю статусов сделки из событий смены статуса
+func leadStatusTimeline(ld *lead, events Events) []LeadStatusPeriod {
+	sorted := make(Events, 0, len(events))
+	for _, e := range events {
+		if e.Type == EventLeadStatusChanged {
+			sorted = append(sorted, e)
+		}
+	}
+
+	// API возвращает события от новых к старым. Разворачиваем их, чтобы события
+	// одной секунды после стабильной сортировки остались в порядке их создания
+	for i, j := 0, len(sorted)-1; i < j; i, j = i+1, j-1 {
+		sorted[i], sorted[j] = sorted[j], sorted[i]
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt < sorted[j].CreatedAt
+	})
+
+	current := LeadStatusPeriod{
+		LeadStage: LeadStage{PipelineId: ld.PipelineId, StatusId: ld.StatusId},
+	}
+	if ld.CreatedAt != 0 {
+		current.From = time.Unix(int64(ld.CreatedAt), 0)
+	}
+
+	if len(sorted) > 0 {
+		if before, ok := eventLeadStage(sorted[0].ValueBefore); ok {
+			current.LeadStage = before
+		}
+	}
+
+	var timeline []LeadStatusPeriod
+	for _, e := range sorted {
+		after, ok := eventLeadStage(e.ValueAfter)
+		if !ok {
+			continue
+		}
+
+		changedAt := time.Unix(int64(e.CreatedAt), 0)
+		current.To = changedAt
+		timeline = append(timeline, current)
+
+		current = LeadStatusPeriod{
+			LeadStage: after,
+			From:      changedAt,
+			ChangedBy: e.CreatedBy,
+		}
+	}
+
+	return append(timeline, current)
+}
+
+func eventLeadStage(values []EventValue) (LeadStage, bool) {
+	for _, v := range values {
+		if v.LeadStatus != nil {
+			return LeadStage{PipelineId: v.LeadStatus.PipelineId, StatusId: v.LeadStatus.Id}, true
+		}
+	}
+
+	return LeadStage{}, false
+}
@@ -5,7 +5,7 @@ import (
 	"net/http"
 )
 
-type Nt struct{}
+type Nt struct{ service }
 
 type NoteType string
 type NoteEntityType string
@@ -32,11 +32,20 @@ const (
 )
 
 const (
-	NoteEntityTypeLead    NoteEntityType = "leads"
-	NoteEntityTypeContact NoteEntityType = "contacts"
+	NoteEntityTypeLead     NoteEntityType = "leads"
+	NoteEntityTypeContact  NoteEntityType = "contacts"
+	NoteEntityTypeCompany  NoteEntityType = "companies"
+	NoteEntityTypeCustomer NoteEntityType = "customers"
 )
 
+// noteBatchSize Максимальное количество примечаний в одном запросе на создание
+const noteBatchSize = 50
+
+type Notes []*note
+
 type note struct {
+	service
+
 	Id                int            `json:"id,omitempty"`
 	EntityId          int            `json:"entity_id,omitempty"`
 	CreatedBy         int            `json:"created_by,omitempty"`
@@ -68,12 +77,11 @@ type noteParams struct {
 	Latitude     string                       `json:"latitude,omitempty"`
 	OriginalName string                       `json:"original_name,omitempty"`
 	Attachment   string                       `json:"attachment,omitempty"`
+	CallResult   string                       `json:"call_result,omitempty"`
+	CallStatus   CallStatusType               `json:"call_status,omitempty"`
 }
 
 type GetNotesQueryParams struct {
-	//entityType        string      `url:"-"`
-	//entityId          int         `url:"-"`
-	//path              string      `url:"-"`
 	Page              int         `url:"page,omitempty"`
 	Limit             int         `url:"limit,omitempty"`
 	Filter            interface{} `url:"filter,omitempty"`
@@ -87,50 +95,205 @@ type allNotes struct {
 	Page     int   `json:"_page"`
 	Links    links `json:"_links,omitempty"`
 	Embedded struct {
-		Notes []*note `json:"notes"`
+		Notes Notes `json:"notes"`
 	} `json:"_embedded"`
 }
 
 // Create выполняет запрос на создание заметки
 func (n *note) Create() (*allNotes, error) {
+	if err := n.Validate(); err != nil {
+		return nil, err
+	}
+
 	path := fmt.Sprintf("/api/v4/%s/notes", n.EntityType)
 
 	req := []note{*n}
 
 	ret := allNotes{}
 
-	return &ret, httpRequest(requestOpts{
+	err := n.api().request(requestOpts{
 		Path:           path,
 		Method:         http.MethodPost,
 		DataParameters: &req,
 		Ret:            &ret,
 	})
+
+	ret.Embedded.Notes.bind(n.service, n.EntityType)
+
+	return &ret, err
+}
+
+// Create Метод позволяет добавлять примечания пакетно. Примечания группируются по типу сущности
+// и отправляются частями не более noteBatchSize штук за запрос.
+func (nt Nt) Create(notes Notes) (Notes, error) {
+	var ret Notes
+
+	var order []NoteEntityType
+	groups := make(map[NoteEntityType]Notes)
+	for _, n := range notes {
+		if n.EntityType == "" {
+			return nil, fmt.Errorf("не указан тип сущности для примечания к сущности %d", n.EntityId)
+		}
+		if err := n.Validate(); err != nil {
+			return nil, fmt.Errorf("примечание к сущности %d: %v", n.EntityId, err)
+		}
+		if _, ok := groups[n.EntityType]; !ok {
+			order = append(order, n.EntityType)
+		}
+		groups[n.EntityType] = append(groups[n.EntityType], n)
+	}
+
+	for _, entity := range order {
+		group := groups[entity]
+
+		for start := 0; start < len(group); start += noteBatchSize {
+			end := start + noteBatchSize
+			if end > len(group) {
+				end = len(group)
+			}
+
+			created, err := nt.create(entity, group[start:end])
+			if err != nil {
+				return ret, err
+			}
+
+			ret = append(ret, created...)
+		}
+	}
+
+	return ret, nil
+}
+
+func (nt Nt) create(entity NoteEntityType, notes Notes) (Notes, error) {
+	ret := allNotes{}
+
+	err := nt.api().request(requestOpts{
+		Method:         http.MethodPost,
+		Path:           fmt.Sprintf("/api/v4/%s/notes", entity),
+		DataParameters: &notes,
+		Ret:            &ret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret.Embedded.Notes.bind(nt.service, entity)
+
+	return ret.Embedded.Notes, nil
+}
+
+// Update Выполняет запрос на изменение заметки
+func (n *note) Update() (*note, error) {
+	ret := note{service: n.service}
+
+	err := n.api().request(requestOpts{
+		Path:           fmt.Sprintf("/api/v4/%s/notes/%d", n.EntityType, n.Id),
+		Method:         http.MethodPatch,
+		DataParameters: &n,
+		Ret:            &ret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret.EntityType = n.EntityType
+
+	return &ret, nil
+}
+
+// Query Метод позволяет получить примечания по типу сущности.
+func (nt Nt) Query(entity NoteEntityType, params *GetNotesQueryParams) (Notes, error) {
+	if params == nil {
+		params = &GetNotesQueryParams{}
+	}
+
+	return nt.multiplyRequest(entity, fmt.Sprintf("/api/v4/%s/notes", entity), params)
+}
+
+// ByEntity Метод позволяет получить примечания конкретной сущности.
+func (nt Nt) ByEntity(entity NoteEntityType, entityId int, params *GetNotesQueryParams) (Notes, error) {
+	if params == nil {
+		params = &GetNotesQueryParams{}
+	}
+
+	return nt.multiplyRequest(entity, fmt.Sprintf("/api/v4/%s/%d/notes", entity, entityId), params)
+}
+
+// ByID Метод позволяет получить примечание по ID.
+func (nt Nt) ByID(entity NoteEntityType, id int) (*note, error) {
+	ret := note{service: nt.service}
+
+	err := nt.api().request(requestOpts{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("/api/v4/%s/notes/%d", entity, id),
+		Ret:    &ret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret.EntityType = entity
+
+	return &ret, nil
+}
+
+// Update Метод позволяет редактировать примечания пакетно.
+func (nt Nt) Update(entity NoteEntityType, notes Notes) (Notes, error) {
+	ret := allNotes{}
+
+	err := nt.api().request(requestOpts{
+		Method:         http.MethodPatch,
+		Path:           fmt.Sprintf("/api/v4/%s/notes", entity),
+		DataParameters: &notes,
+		Ret:            &ret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret.Embedded.Notes.bind(nt.service, entity)
+
+	return ret.Embedded.Notes, nil
 }
 
-//func noteMultiplyRequest(params GetNotesQueryParams) ([]*note, error) {
-//	var notes []*note
-//
-//	for {
-//		var tmpNotes allNotes
-//
-//		err := httpRequest(requestOpts{
-//			Method:        http.MethodGet,
-//			Path:          params.path,
-//			URLParameters: &params,
-//			Ret:           &tmpNotes,
-//		})
-//		if err != nil {
-//			return nil, err
-//		}
-//
-//		notes = append(notes, tmpNotes.Embedded.Notes...)
-//
-//		if len(tmpNotes.Links.Next.Href) > 0 {
-//			params.page = tmpNotes.Page + 1
-//		} else {
-//			break
-//		}
-//	}
-//
-//	return notes, nil
-//}
+func (nt Nt) multiplyRequest(entity NoteEntityType, path string, params *GetNotesQueryParams) (Notes, error) {
+	var notes Notes
+
+	if params.Limit == 0 {
+		params.Limit = 250
+	}
+
+	for {
+		var tmpNotes allNotes
+
+		err := nt.api().request(requestOpts{
+			Method:        http.MethodGet,
+			Path:          path,
+			URLParameters: &params,
+			Ret:           &tmpNotes,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ошибка обработки запроса %s: %w", path, err)
+		}
+
+		tmpNotes.Embedded.Notes.bind(nt.service, entity)
+
+		notes = append(notes, tmpNotes.Embedded.Notes...)
+
+		if len(tmpNotes.Links.Next.Href) > 0 {
+			params.Page = tmpNotes.Page + 1
+		} else {
+			break
+		}
+	}
+
+	return notes, nil
+}
+
+// bind Проставляет примечаниям аккаунт и тип сущности, которые не возвращаются в ответе API
+func (ns Notes) bind(s service, entity NoteEntityType) {
+	for _, n := range ns {
+		n.service = s
+		n.EntityType = entity
+	}
+}
@@ -0,0 +1,253 @@
+package amocrm_v4
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type CallStatusType int
+
+const (
+	CallStatusVoicemail    CallStatusType = iota + 1 // Оставил сообщение
+	CallStatusCallLater                              // Перезвонить позже
+	CallStatusNotAvailable                           // Нет на месте
+	CallStatusSuccess                                // Разговор состоялся
+	CallStatusWrongNumber                            // Неверный номер
+	CallStatusNoAnswer                               // Не дозвонился
+	CallStatusBusy                                   // Номер занят
+)
+
+// CallNoteParams Параметры примечаний call_in и call_out
+type CallNoteParams struct {
+	Uniq       string         // Уникальный идентификатор звонка. Обязательный параметр
+	Duration   int            // Длительность звонка в секундах
+	Source     string         // Источник звонка. Обязательный параметр
+	Link       string         // Ссылка на запись звонка
+	Phone      string         // Номер телефона. Обязательный параметр
+	CallResult string         // Результат звонка
+	CallStatus CallStatusType // Статус звонка
+}
+
+// SmsNoteParams Параметры примечаний sms_in и sms_out
+type SmsNoteParams struct {
+	Service string // Название сервиса. Обязательный параметр
+	Text    string // Текст сообщения. Обязательный параметр
+	Phone   string // Номер телефона. Обязательный параметр
+}
+
+// ServiceMessageNoteParams Параметры примечаний service_message и extended_service_message
+type ServiceMessageNoteParams struct {
+	Service string // Название сервиса. Обязательный параметр
+	Text    string // Текст примечания. Обязательный параметр для service_message
+}
+
+// MessageCashierNoteParams Параметры примечания message_cashier
+type MessageCashierNoteParams struct {
+	Status MessageCashierNoteStatusType // Статус сообщения. Обязательный параметр
+	Text   string                       // Текст сообщения. Обязательный параметр
+}
+
+// InvoicePaidNoteParams Параметры примечания invoice_paid
+type InvoicePaidNoteParams struct {
+	Text    string // Текст примечания. Обязательный параметр
+	Service string // Название сервиса, которым была произведена оплата. Обязательный параметр
+	IconUrl string // Ссылка на иконку сервиса оплаты
+}
+
+// GeolocationNoteParams Параметры примечания geolocation
+type GeolocationNoteParams struct {
+	Text      string  // Текст примечания. Обязательный параметр
+	Address   string  // Адрес. Обязательный параметр
+	Latitude  float64 // Широта. Обязательный параметр
+	Longitude float64 // Долгота. Обязательный параметр
+}
+
+// AttachmentNoteParams Параметры примечания attachment
+type AttachmentNoteParams struct {
+	OriginalName string // Название файла. Обязательный параметр
+	Attachment   string // Имя загруженного файла. Обязательный параметр
+}
+
+// Common Заполняет примечание как обычное текстовое
+func (n *note) Common(text string) *note {
+	n.NoteType = CommonNote
+	n.Params = noteParams{Text: text}
+
+	return n
+}
+
+// CallIn Заполняет примечание как входящий звонок
+func (n *note) CallIn(p CallNoteParams) *note {
+	n.NoteType = CallInNote
+	n.Params = p.params()
+
+	return n
+}
+
+// CallOut Заполняет примечание как исходящий звонок
+func (n *note) CallOut(p CallNoteParams) *note {
+	n.NoteType = CallOutNote
+	n.Params = p.params()
+
+	return n
+}
+
+// SmsIn Заполняет примечание как входящее SMS
+func (n *note) SmsIn(p SmsNoteParams) *note {
+	n.NoteType = SmsInNote
+	n.Params = noteParams{Service: p.Service, Text: p.Text, Phone: p.Phone}
+
+	return n
+}
+
+// SmsOut Заполняет примечание как исходящее SMS
+func (n *note) SmsOut(p SmsNoteParams) *note {
+	n.NoteType = SmsOutNote
+	n.Params = noteParams{Service: p.Service, Text: p.Text, Phone: p.Phone}
+
+	return n
+}
+
+// ServiceMessage Заполняет примечание как системное сообщение
+func (n *note) ServiceMessage(p ServiceMessageNoteParams) *note {
+	n.NoteType = ServiceMessageNote
+	n.Params = noteParams{Service: p.Service, Text: p.Text}
+
+	return n
+}
+
+// ExtendedServiceMessage Заполняет примечание как расширенное системное сообщение
+func (n *note) ExtendedServiceMessage(p ServiceMessageNoteParams) *note {
+	n.NoteType = ExtendedServiceMessageNote
+	n.Params = noteParams{Service: p.Service, Text: p.Text}
+
+	return n
+}
+
+// MessageCashier Заполняет примечание как сообщение кассиру
+func (n *note) MessageCashier(p MessageCashierNoteParams) *note {
+	n.NoteType = MessageCashierNote
+	n.Params = noteParams{Status: p.Status, Text: p.Text}
+
+	return n
+}
+
+// InvoicePaid Заполняет примечание как оплату счета
+func (n *note) InvoicePaid(p InvoicePaidNoteParams) *note {
+	n.NoteType = InvoicePaidNote
+	n.Params = noteParams{Text: p.Text, Service: p.Service, IconUrl: p.IconUrl}
+
+	return n
+}
+
+// Geolocation Заполняет примечание как геолокацию
+func (n *note) Geolocation(p GeolocationNoteParams) *note {
+	n.NoteType = GeolocationNote
+	n.Params = noteParams{
+		Text:      p.Text,
+		Address:   p.Address,
+		Latitude:  strconv.FormatFloat(p.Latitude, 'f', -1, 64),
+		Longitude: strconv.FormatFloat(p.Longitude, 'f', -1, 64),
+	}
+
+	return n
+}
+
+// Attachment Заполняет примечание как файл
+func (n *note) Attachment(p AttachmentNoteParams) *note {
+	n.NoteType = AttachmentNote
+	n.Params = noteParams{OriginalName: p.OriginalName, Attachment: p.Attachment}
+
+	return n
+}
+
+func (p CallNoteParams) params() noteParams {
+	return noteParams{
+		Uniq:       p.Uniq,
+		Duration:   p.Duration,
+		Source:     p.Source,
+		Link:       p.Link,
+		Phone:      p.Phone,
+		CallResult: p.CallResult,
+		CallStatus: p.CallStatus,
+	}
+}
+
+// Validate Проверяет, что для типа примечания заполнены обязательные параметры
+func (n *note) Validate() error {
+	p := n.Params
+
+	var missing []string
+	require := func(ok bool, name string) {
+		if !ok {
+			missing = append(missing, name)
+		}
+	}
+
+	switch n.NoteType {
+	case CommonNote:
+		require(p.Text != "", "text")
+	case CallInNote, CallOutNote:
+		require(p.Uniq != "", "uniq")
+		require(p.Source != "", "source")
+		require(p.Phone != "", "phone")
+		if p.CallStatus < 0 || p.CallStatus > CallStatusBusy {
+			return fmt.Errorf("примечание %s: недопустимый call_status %d", n.NoteType, p.CallStatus)
+		}
+	case SmsInNote, SmsOutNote:
+		require(p.Service != "", "service")
+		require(p.Text != "", "text")
+		require(p.Phone != "", "phone")
+	case ServiceMessageNote:
+		require(p.Service != "", "service")
+		require(p.Text != "", "text")
+	case ExtendedServiceMessageNote:
+		require(p.Service != "", "service")
+	case MessageCashierNote:
+		require(p.Status != "", "status")
+		require(p.Text != "", "text")
+	case InvoicePaidNote:
+		require(p.Text != "", "text")
+		require(p.Service != "", "service")
+	case GeolocationNote:
+		require(p.Text != "", "text")
+		require(p.Address != "", "address")
+		require(p.Latitude != "", "latitude")
+		require(p.Longitude != "", "longitude")
+		if err := validateCoordinates(p.Latitude, p.Longitude); err != nil {
+			return fmt.Errorf("примечание %s: %v", n.NoteType, err)
+		}
+	case AttachmentNote:
+		require(p.OriginalName != "", "original_name")
+		require(p.Attachment != "", "attachment")
+	case "":
+		return fmt.Errorf("не указан тип примечания")
+	default:
+		return fmt.Errorf("неизвестный тип примечания %s", n.NoteType)
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("примечание %s: не заполнены обязательные параметры: %s", n.NoteType, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func validateCoordinates(lat, lon string) error {
+	if lat == "" || lon == "" {
+		return nil
+	}
+
+	latitude, err := strconv.ParseFloat(lat, 64)
+	if err != nil || latitude < -90 || latitude > 90 {
+		return fmt.Errorf("некорректная широта %q", lat)
+	}
+
+	longitude, err := strconv.ParseFloat(lon, 64)
+	if err != nil || longitude < -180 || longitude > 180 {
+		return fmt.Errorf("некорректная долгота %q", lon)
+	}
+
+	return nil
+}
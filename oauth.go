@@ -0,0 +1,272 @@
+package amocrm_v4
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type OAuthMode string
+
+const (
+	OAuthModePopup       OAuthMode = "popup"        // Окно авторизации закроется, а пользователь останется на странице интеграции
+	OAuthModePostMessage OAuthMode = "post_message" // Результат будет передан в открывшее окно через postMessage
+
+	// oauthConsentURL Адрес страницы предоставления доступа
+	oauthConsentURL = "https://www.amocrm.ru/oauth"
+
+	defaultOAuthStateTTL = 10 * time.Minute
+)
+
+// oauthReferer Допустимые адреса аккаунтов, в которые можно отправить код авторизации
+var oauthReferer = regexp.MustCompile(`^([a-z0-9][a-z0-9-]*)\.(amocrm\.ru|amocrm\.com|kommo\.com)$`)
+
+// OAuthToken Результат обмена кода авторизации на токены
+type OAuthToken struct {
+	AccountId    int    // ID аккаунта. Заполняется, если токены сохранены через TokenManager
+	Domain       string // Поддомен аккаунта
+	Referer      string // Адрес аккаунта, например example.amocrm.ru
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time // Время, до которого действует access_token
+	State        string    // state, переданный на страницу предоставления доступа
+}
+
+// OAuthCallbackConfig Параметры обработчика redirect_uri
+type OAuthCallbackConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	// Manager Менеджер токенов, через который сохраняются токены каждого аккаунта (см. TokenManager.Install)
+	Manager *TokenManager
+	// Storage Хранилище токенов одного аккаунта для клиента NewClient. Установка в другой аккаунт
+	// перезапишет сохраненный токен, поэтому для нескольких аккаунтов используйте Manager
+	Storage *AuthAmoStorageConfig
+
+	// VerifyState Проверяет state, выданный при формировании ссылки. Обязательный параметр
+	VerifyState func(state string) bool
+	// OnSuccess Вызывается после сохранения токенов и формирует ответ пользователю
+	OnSuccess func(w http.ResponseWriter, r *http.Request, token *OAuthToken)
+	// OnError Вызывается при ошибке и формирует ответ пользователю
+	OnError func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// OAuthStates Хранилище выданных state с ограниченным временем жизни. Каждый state можно проверить один раз.
+type OAuthStates struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	states map[string]time.Time
+}
+
+type oauthCallbackHandler struct {
+	cfg OAuthCallbackConfig
+}
+
+// AuthorizeURL Формирует ссылку на страницу предоставления доступа интеграции
+func AuthorizeURL(clientID, state string, mode OAuthMode) string {
+	params := url.Values{}
+	params.Set("client_id", clientID)
+	params.Set("state", state)
+	if mode != "" {
+		params.Set("mode", string(mode))
+	}
+
+	return fmt.Sprintf("%s?%s", oauthConsentURL, params.Encode())
+}
+
+// GenerateState Возвращает случайную строку для параметра state
+func GenerateState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// NewOAuthStates Создает хранилище state. При нулевом ttl state действует 10 минут.
+func NewOAuthStates(ttl time.Duration) *OAuthStates {
+	if ttl <= 0 {
+		ttl = defaultOAuthStateTTL
+	}
+
+	return &OAuthStates{ttl: ttl, states: make(map[string]time.Time)}
+}
+
+// New Выдает новый state
+func (s *OAuthStates) New() (string, error) {
+	state, err := GenerateState()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, exp := range s.states {
+		if now.After(exp) {
+			delete(s.states, k)
+		}
+	}
+	s.states[state] = now.Add(s.ttl)
+
+	return state, nil
+}
+
+// Verify Проверяет, что state был выдан и не истек. Проверенный state удаляется.
+func (s *OAuthStates) Verify(state string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exp, ok := s.states[state]
+	if !ok {
+		return false
+	}
+	delete(s.states, state)
+
+	return time.Now().Before(exp)
+}
+
+// NewOAuthCallbackHandler Возвращает http.Handler для redirect_uri интеграции. Обработчик проверяет state,
+// обменивает code на токены в аккаунте из referer и сохраняет refresh_token в хранилище.
+// Возвращает ошибку, если не указан VerifyState.
+func NewOAuthCallbackHandler(cfg OAuthCallbackConfig) (http.Handler, error) {
+	if cfg.VerifyState == nil {
+		return nil, errors.New("не указан VerifyState для обработчика OAuth")
+	}
+
+	return &oauthCallbackHandler{cfg: cfg}, nil
+}
+
+func (h *oauthCallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	if e := q.Get("error"); e != "" {
+		h.fail(w, r, http.StatusForbidden, fmt.Errorf("доступ не предоставлен: %s", e))
+		return
+	}
+
+	state := q.Get("state")
+	if state == "" || !h.cfg.VerifyState(state) {
+		h.fail(w, r, http.StatusBadRequest, errors.New("некорректный state"))
+		return
+	}
+
+	code := q.Get("code")
+	if code == "" {
+		h.fail(w, r, http.StatusBadRequest, errors.New("не передан code"))
+		return
+	}
+
+	referer := strings.ToLower(q.Get("referer"))
+	if !oauthReferer.MatchString(referer) {
+		h.fail(w, r, http.StatusBadRequest, fmt.Errorf("некорректный адрес аккаунта %q", referer))
+		return
+	}
+
+	token, err := ExchangeCode(referer, h.cfg.ClientID, h.cfg.ClientSecret, h.cfg.RedirectURI, code)
+	if err != nil {
+		h.fail(w, r, http.StatusBadGateway, err)
+		return
+	}
+	token.State = state
+
+	if h.cfg.Manager != nil {
+		amo, err := h.cfg.Manager.Install(token)
+		if err != nil {
+			h.fail(w, r, http.StatusInternalServerError, fmt.Errorf("ошибка при сохранении токенов: %v", err))
+			return
+		}
+		token.AccountId = amo.api().accountId
+	} else if h.cfg.Storage != nil {
+		err = saveAuthDataInDB(h.cfg.Storage, token.RefreshToken, token.ExpiresAt)
+		if err != nil {
+			h.fail(w, r, http.StatusInternalServerError, fmt.Errorf("ошибка при сохранении токенов: %v", err))
+			return
+		}
+	}
+
+	log.Infof("Интеграция установлена в аккаунт %s", token.Referer)
+
+	if h.cfg.OnSuccess != nil {
+		h.cfg.OnSuccess(w, r, token)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("Интеграция установлена"))
+}
+
+func (h *oauthCallbackHandler) fail(w http.ResponseWriter, r *http.Request, status int, err error) {
+	log.Errorf("Ошибка авторизации интеграции: %v", err)
+
+	if h.cfg.OnError != nil {
+		h.cfg.OnError(w, r, err)
+		return
+	}
+
+	http.Error(w, err.Error(), status)
+}
+
+// ExchangeCode Обменивает код авторизации на токены в аккаунте referer (например, example.amocrm.ru)
+func ExchangeCode(referer, clientID, clientSecret, redirectURI, code string) (*OAuthToken, error) {
+	referer = strings.ToLower(strings.TrimSpace(referer))
+	m := oauthReferer.FindStringSubmatch(referer)
+	if m == nil {
+		return nil, fmt.Errorf("некорректный адрес аккаунта %q", referer)
+	}
+
+	// код обменивается в аккаунте из referer, клиент NewClient для этого не нужен
+	a := &authSettings{
+		client:   http.Client{},
+		endpoint: "https://" + referer,
+	}
+
+	ret := authResp{}
+	err := a.request(requestOpts{
+		Method: http.MethodPost,
+		Path:   "/oauth2/access_token",
+		DataParameters: &authRequest{
+			ClientId:     clientID,
+			ClientSecret: clientSecret,
+			GrantType:    amoAuthorizationAuthCode,
+			Code:         code,
+			RedirectUri:  redirectURI,
+		},
+		Ret: &ret,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка обмена кода авторизации: %v", err)
+	}
+
+	return &OAuthToken{
+		Domain:       m[1],
+		Referer:      referer,
+		AccessToken:  ret.AccessToken,
+		RefreshToken: ret.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(ret.ExpiresIn)*time.Second - 1*time.Minute),
+	}, nil
+}
+
+// saveAuthDataInDB Создает или обновляет запись об авторизации приложения
+func saveAuthDataInDB(storage *AuthAmoStorageConfig, refreshToken string, expiresIn time.Time) error {
+	_, err := getAuthDataFromDB(storage)
+	if errors.Is(err, errAuthDataNotFound) {
+		return createAuthDataInDB(storage, refreshToken, expiresIn)
+	}
+	if err != nil {
+		return err
+	}
+
+	return updateAuthDataInDB(storage, refreshToken, expiresIn)
+}
@@ -3,6 +3,7 @@ package amocrm_v4
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/google/go-querystring/query"
 	log "github.com/sirupsen/logrus"
@@ -18,6 +19,12 @@ type requestOpts struct {
 	URLParameters  interface{}
 	DataParameters interface{}
 	Ret            interface{}
+
+	// AllowMultiStatus Принимать ответ 207 Multi-Status при частично успешных пакетных запросах (например, /api/v4/calls)
+	AllowMultiStatus bool
+
+	// Sign Подписывает запрос вместо заголовка Authorization, например для API чатов
+	Sign func(req *http.Request, body []byte)
 }
 
 type errorResponse struct {
@@ -35,7 +42,67 @@ type errorResponse struct {
 	Detail string `json:"detail"`
 }
 
+// ValidationError Ошибка валидации одного поля запроса
+type ValidationError struct {
+	RequestId string // request_id сущности, в которой найдена ошибка
+	Code      string // Код ошибки
+	Path      string // Путь к полю с ошибкой
+	Detail    string // Описание ошибки
+}
+
+// APIError Ошибка, которую вернул API amoCRM
+type APIError struct {
+	StatusCode       int               // HTTP код ответа
+	Path             string            // Путь запроса, на который получена ошибка
+	Status           string            // HTTP статус ответа
+	Title            string            // Заголовок ошибки
+	Detail           string            // Описание ошибки
+	ValidationErrors []ValidationError // Ошибки валидации по полям
+	Body             []byte            // Тело ответа
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Status, e.describe())
+}
+
+func (e *APIError) describe() string {
+	var errorString []string
+	for _, v := range e.ValidationErrors {
+		errorString = append(errorString, fmt.Sprintf("%s: %s", v.Path, v.Detail))
+	}
+
+	if len(errorString) == 0 {
+		if e.Detail != "" {
+			return e.Detail
+		}
+		if e.Title != "" {
+			return e.Title
+		}
+		return string(e.Body)
+	}
+
+	return strings.Join(errorString, " | ")
+}
+
+// httpRequest Выполняет запрос в аккаунт клиента, созданного NewClient
 func httpRequest(opts requestOpts) error {
+	return client.request(opts)
+}
+
+// ErrNoClient Сущность не привязана к аккаунту, а клиент NewClient не создан.
+// Сущности, полученные не через API (например, из вебхуков), нужно привязать к клиенту аккаунта.
+var ErrNoClient = errors.New("сущность не привязана к аккаунту, клиент не создан")
+
+// request Выполняет запрос в аккаунт a
+func (a *authSettings) request(opts requestOpts) error {
+	if a == &client && !client.initialized() {
+		return ErrNoClient
+	}
+
+	if a.isDisconnected() {
+		return ErrAccountDisconnected
+	}
+
 	var buf bytes.Buffer
 
 	if opts.DataParameters != nil {
@@ -50,7 +117,7 @@ func httpRequest(opts requestOpts) error {
 		return err
 	}
 
-	requestURL := client.getUrl(opts.Path)
+	requestURL := a.getUrl(opts.Path)
 	if len(values) > 0 {
 		requestURL += "?" + values.Encode()
 	}
@@ -59,6 +126,8 @@ func httpRequest(opts requestOpts) error {
 	log.Debugf("URL Parameters: %s", values.Encode())
 	log.Debugf("Body Parameters: %s", buf.String())
 
+	data := buf.Bytes()
+
 	req, err := http.NewRequest(opts.Method, requestURL, &buf)
 	if err != nil {
 		return err
@@ -66,14 +135,19 @@ func httpRequest(opts requestOpts) error {
 
 	req.Header.Add("Content-Type", "application/json")
 
-	if opts.Path != "/oauth2/access_token" {
-		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", client.accessToken))
+	if opts.Sign != nil {
+		opts.Sign(req, data)
+	} else if opts.Path != "/oauth2/access_token" {
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", a.token()))
 	}
 
 	log.Debugf("Request Headers: %s", req.Header)
 	log.Debugf("Request: %+v", req)
 
-	resp, err := client.client.Do(req)
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
 
 	log.Debugf("Response: %+v", resp)
 
@@ -95,8 +169,12 @@ func httpRequest(opts requestOpts) error {
 		return nil
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("%s: %s", resp.Status, errorResponseFormat(body))
+	if opts.Ret == nil && resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	if resp.StatusCode != http.StatusOK && !(opts.AllowMultiStatus && resp.StatusCode == http.StatusMultiStatus) {
+		return newAPIError(resp, body)
 	}
 
 	err = json.Unmarshal(body, &opts.Ret)
@@ -107,20 +185,33 @@ func httpRequest(opts requestOpts) error {
 	return nil
 }
 
-func errorResponseFormat(body []byte) string {
-	resp := errorResponse{}
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       body,
+	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		apiErr.Path = resp.Request.URL.Path
+	}
 
-	err := json.Unmarshal(body, &resp)
-	if err != nil {
-		return fmt.Sprintf("%s", err)
+	errResp := errorResponse{}
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		return apiErr
 	}
 
-	var errorString []string
-	for _, vErr := range resp.ValidationErrors {
+	apiErr.Title = errResp.Title
+	apiErr.Detail = errResp.Detail
+	for _, vErr := range errResp.ValidationErrors {
 		for _, v := range vErr.Errors {
-			errorString = append(errorString, fmt.Sprintf("%s: %s", v.Path, v.Detail))
+			apiErr.ValidationErrors = append(apiErr.ValidationErrors, ValidationError{
+				RequestId: vErr.RequestId,
+				Code:      v.Code,
+				Path:      v.Path,
+				Detail:    v.Detail,
+			})
 		}
 	}
 
-	return strings.Join(errorString, " | ")
+	return apiErr
 }
@@ -1,18 +1,189 @@
 package amocrm_v4
 
-type Tg struct{}
+import (
+	"fmt"
+	"net/http"
+)
+
+type (
+	Tg            struct{ service }
+	TagEntityType string
+	Tags          []Tag
+)
+
+const (
+	TagsForLeads     TagEntityType = "leads"
+	TagsForContacts  TagEntityType = "contacts"
+	TagsForCompanies TagEntityType = "companies"
+	TagsForCustomers TagEntityType = "customers"
+)
 
 type allTags struct {
 	Page     int   `json:"_page"`
 	Links    links `json:"_links"`
 	Embedded struct {
-		Tags []Tag `json:"tags"`
+		Tags Tags `json:"tags"`
 	} `json:"_embedded"`
 }
 
 type Tag struct {
 	Id        int         `json:"id,omitempty"`
-	Name      string      `json:"name"`
-	Color     interface{} `json:"color"`
-	RequestID string      `json:"request_id"`
+	Name      string      `json:"name,omitempty"`
+	Color     interface{} `json:"color,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+type GetTagsQueryParams struct {
+	Page         int    `url:"page,omitempty"`         // Страница выборки
+	Limit        int    `url:"limit,omitempty"`        // Количество возвращаемых сущностей за один запрос (Максимум – 250)
+	FilterByName string `url:"filter[name],omitempty"` // Фильтр по точному названию тега
+	FilterById   []int  `url:"filter[id][],omitempty"` // Фильтр по ID тега
+	Query        string `url:"query,omitempty"`        // Поиск по подстроке в названии тега
+}
+
+// entityTags Тело запроса на изменение тегов сущности без перезаписи остальных тегов
+type entityTags struct {
+	Id           int   `json:"id"`
+	TagsToAdd    []Tag `json:"tags_to_add,omitempty"`
+	TagsToDelete []Tag `json:"tags_to_delete,omitempty"`
+}
+
+// All Метод позволяет получить список тегов для сущности в аккаунте.
+func (t Tg) All(entity TagEntityType) (Tags, error) {
+	return t.multiplyRequest(entity, &GetTagsQueryParams{
+		Limit: 250,
+	})
+}
+
+// Query Метод позволяет найти теги сущности по названию или ID.
+func (t Tg) Query(entity TagEntityType, params *GetTagsQueryParams) (Tags, error) {
+	if params.Limit == 0 {
+		params.Limit = 250
+	}
+
+	return t.multiplyRequest(entity, params)
+}
+
+// Create Метод позволяет добавлять теги пакетно.
+func (t Tg) Create(entity TagEntityType, tags Tags) (*allTags, error) {
+	ret := allTags{}
+
+	return &ret, t.api().request(requestOpts{
+		Method:         http.MethodPost,
+		Path:           fmt.Sprintf("/api/v4/%s/tags", entity),
+		DataParameters: &tags,
+		Ret:            &ret,
+	})
+}
+
+// Delete Метод позволяет удалять теги пакетно. Теги можно передать по ID или по названию.
+func (t Tg) Delete(entity TagEntityType, tags Tags) error {
+	return t.api().request(requestOpts{
+		Method:         http.MethodDelete,
+		Path:           fmt.Sprintf("/api/v4/%s/tags", entity),
+		DataParameters: &tags,
+	})
+}
+
+// AddTo Добавляет теги к существующим сущностям, не затрагивая уже установленные теги.
+// Теги можно передать по ID или по названию, несуществующие теги будут созданы.
+func (t Tg) AddTo(entity TagEntityType, ids []int, tags ...Tag) error {
+	req := make([]entityTags, 0, len(ids))
+	for _, id := range ids {
+		req = append(req, entityTags{Id: id, TagsToAdd: tags})
+	}
+
+	return t.updateEntities(entity, req)
+}
+
+// RemoveFrom Удаляет теги у существующих сущностей, не затрагивая остальные теги.
+func (t Tg) RemoveFrom(entity TagEntityType, ids []int, tags ...Tag) error {
+	req := make([]entityTags, 0, len(ids))
+	for _, id := range ids {
+		req = append(req, entityTags{Id: id, TagsToDelete: tags})
+	}
+
+	return t.updateEntities(entity, req)
+}
+
+// AddTags Добавляет теги к сделке, не затрагивая уже установленные теги
+func (l *lead) AddTags(names ...string) error {
+	return Tg{l.service}.AddTo(TagsForLeads, []int{l.Id}, tagsByName(names)...)
+}
+
+// RemoveTags Удаляет теги у сделки, не затрагивая остальные теги
+func (l *lead) RemoveTags(names ...string) error {
+	return Tg{l.service}.RemoveFrom(TagsForLeads, []int{l.Id}, tagsByName(names)...)
+}
+
+// AddTags Добавляет теги к контакту, не затрагивая уже установленные теги
+func (ct *contact) AddTags(names ...string) error {
+	return Tg{ct.service}.AddTo(TagsForContacts, []int{ct.Id}, tagsByName(names)...)
+}
+
+// RemoveTags Удаляет теги у контакта, не затрагивая остальные теги
+func (ct *contact) RemoveTags(names ...string) error {
+	return Tg{ct.service}.RemoveFrom(TagsForContacts, []int{ct.Id}, tagsByName(names)...)
+}
+
+// AddTags Добавляет теги к компании, не затрагивая уже установленные теги
+func (cmp *company) AddTags(names ...string) error {
+	return Tg{cmp.service}.AddTo(TagsForCompanies, []int{cmp.Id}, tagsByName(names)...)
+}
+
+// RemoveTags Удаляет теги у компании, не затрагивая остальные теги
+func (cmp *company) RemoveTags(names ...string) error {
+	return Tg{cmp.service}.RemoveFrom(TagsForCompanies, []int{cmp.Id}, tagsByName(names)...)
+}
+
+func (t Tg) updateEntities(entity TagEntityType, req []entityTags) error {
+	if len(req) == 0 {
+		return nil
+	}
+
+	return t.api().request(requestOpts{
+		Method:         http.MethodPatch,
+		Path:           fmt.Sprintf("/api/v4/%s", entity),
+		DataParameters: &req,
+		Ret:            &struct{}{},
+	})
+}
+
+func (t Tg) multiplyRequest(entity TagEntityType, params *GetTagsQueryParams) (Tags, error) {
+	var tags Tags
+
+	path := fmt.Sprintf("/api/v4/%s/tags", entity)
+
+	for {
+		var tmpTags allTags
+
+		err := t.api().request(requestOpts{
+			Method:        http.MethodGet,
+			Path:          path,
+			URLParameters: &params,
+			Ret:           &tmpTags,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ошибка обработки запроса %s: %w", path, err)
+		}
+
+		tags = append(tags, tmpTags.Embedded.Tags...)
+
+		if len(tmpTags.Links.Next.Href) > 0 {
+			params.Page = tmpTags.Page + 1
+		} else {
+			break
+		}
+	}
+
+	return tags, nil
+}
+
+func tagsByName(names []string) []Tag {
+	tags := make([]Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, Tag{Name: name})
+	}
+
+	return tags
 }
@@ -6,7 +6,7 @@ import (
 )
 
 type (
-	Tsk                     struct{}
+	Tsk                     struct{ service }
 	TaskTypeIdType          int
 	TaskEntityType          string
 	FilterByIsCompletedType int
@@ -38,6 +38,8 @@ const (
 )
 
 type task struct {
+	service
+
 	Id                int            `json:"id,omitempty"`                  // Id задачи
 	CreatedBy         int            `json:"created_by,omitempty"`          // ID пользователя, создавшего задачу
 	UpdatedBy         int            `json:"updated_by,omitempty"`          // ID пользователя, изменившего задачу
@@ -68,7 +70,7 @@ type allTasks struct {
 	Page     int   `json:"_page,omitempty"`
 	Links    links `json:"_links"`
 	Embedded struct {
-		Tasks []*task `json:"tasks"`
+		Tasks Tasks `json:"tasks"`
 	} `json:"_embedded"`
 }
 
@@ -90,45 +92,69 @@ type GetTaskQueryParams struct {
 }
 
 func (t Tsk) New() *task {
-	return &task{}
+	return &task{service: t.service}
 }
 
 // Create Создает новую задачу.
 // Для создания задачи нужно передать 2 обязательных параметра:
 // text и complete_till.
 func (t Tsk) Create(tsk Tasks) (*allTasks, error) {
+	if err := validateTaskTypes(t.api(), tsk); err != nil {
+		return nil, err
+	}
+
 	ret := allTasks{}
 
-	return &ret, httpRequest(requestOpts{
+	err := t.api().request(requestOpts{
 		Method:         http.MethodPost,
 		Path:           "/api/v4/tasks",
 		DataParameters: &tsk,
 		Ret:            &ret,
 	})
+
+	ret.Embedded.Tasks.bind(t.service)
+
+	return &ret, err
 }
 
 // Update Обновляет задачу. Данный метод может использоваться для пакетного обновления задач.
 func (t Tsk) Update(tsk Tasks) (*allTasks, error) {
+	if err := validateTaskTypes(t.api(), tsk); err != nil {
+		return nil, err
+	}
+
 	ret := allTasks{}
 
-	return &ret, httpRequest(requestOpts{
+	err := t.api().request(requestOpts{
 		Method:         http.MethodPatch,
 		Path:           "/api/v4/tasks",
 		DataParameters: &tsk,
 		Ret:            &ret,
 	})
+
+	ret.Embedded.Tasks.bind(t.service)
+
+	return &ret, err
 }
 
 // Update Обновляет задачу. Данный метод используется для индивидуального обновления задачи.
 func (t *task) Update() (*allTasks, error) {
+	if err := validateTaskTypes(t.api(), Tasks{t}); err != nil {
+		return nil, err
+	}
+
 	ret := allTasks{}
 
-	return &ret, httpRequest(requestOpts{
+	err := t.api().request(requestOpts{
 		Method:         http.MethodPatch,
 		Path:           fmt.Sprintf("/api/v4/tasks/%d", t.Id),
 		DataParameters: &t,
 		Ret:            &ret,
 	})
+
+	ret.Embedded.Tasks.bind(t.service)
+
+	return &ret, err
 }
 
 //// Complete Обновляет задаче статус выполнения. Данный метод может использоваться для пакетного обновления задач.
@@ -148,7 +174,7 @@ func (t *task) Complete(result string) (*task, error) {
 	t.IsCompleted = true
 	t.Result.Text = result
 
-	return t, httpRequest(requestOpts{
+	return t, t.api().request(requestOpts{
 		Method:         http.MethodPatch,
 		Path:           fmt.Sprintf("/api/v4/tasks/%d", t.Id),
 		DataParameters: &t,
@@ -175,9 +201,9 @@ func (t Tsk) Query(params GetTaskQueryParams) (Tasks, error) {
 }
 
 func (t Tsk) ByID(id int) (*task, error) {
-	ret := task{}
+	ret := task{service: t.service}
 
-	return &ret, httpRequest(requestOpts{
+	return &ret, t.api().request(requestOpts{
 		Method: http.MethodGet,
 		Path:   fmt.Sprintf("/api/v4/tasks/%d", id),
 		Ret:    &ret,
@@ -192,7 +218,7 @@ func (t Tsk) multiplyRequest(params *GetTaskQueryParams) (Tasks, error) {
 	for {
 		var tmpTasks allTasks
 
-		err := httpRequest(requestOpts{
+		err := t.api().request(requestOpts{
 			Method:        http.MethodGet,
 			Path:          path,
 			URLParameters: &params,
@@ -202,6 +228,7 @@ func (t Tsk) multiplyRequest(params *GetTaskQueryParams) (Tasks, error) {
 			return nil, err
 		}
 
+		tmpTasks.Embedded.Tasks.bind(t.service)
 		tasks = append(tasks, tmpTasks.Embedded.Tasks...)
 
 		if len(tmpTasks.Links.Next.Href) > 0 {
@@ -213,3 +240,9 @@ func (t Tsk) multiplyRequest(params *GetTaskQueryParams) (Tasks, error) {
 
 	return tasks, nil
 }
+
+func (ts Tasks) bind(s service) {
+	for _, t := range ts {
+		t.service = s
+	}
+}
@@ -0,0 +1,238 @@
+package amocrm_v4
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// tokenDataKeySize Размер ключа, которым шифруется каждый токен
+const tokenDataKeySize = 32
+
+// TokenEncryptor Шифрует токены перед сохранением по схеме envelope encryption:
+// каждый токен шифруется своим случайным ключом AES-256-GCM, а этот ключ шифруется ключом ActiveKeyID.
+// ID ключа хранится рядом с токеном, поэтому после ротации старые записи продолжают читаться.
+type TokenEncryptor struct {
+	keys        map[string]cipher.AEAD
+	activeKeyID string
+}
+
+// EncryptedTokenStore Хранилище токенов, которое шифрует refresh_token поверх любого TokenStore
+type EncryptedTokenStore struct {
+	store TokenStore
+	enc   *TokenEncryptor
+}
+
+// NewTokenEncryptor Создает шифратор. keys – ключи по ID (16, 24 или 32 байта),
+// activeKeyID – ключ, которым шифруются новые записи.
+func NewTokenEncryptor(activeKeyID string, keys map[string][]byte) (*TokenEncryptor, error) {
+	if activeKeyID == "" {
+		return nil, errors.New("не указан ID активного ключа")
+	}
+
+	e := &TokenEncryptor{keys: make(map[string]cipher.AEAD, len(keys)), activeKeyID: activeKeyID}
+	for id, key := range keys {
+		if id == "" {
+			return nil, errors.New("пустой ID ключа")
+		}
+
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, fmt.Errorf("ключ %s: %v", id, err)
+		}
+		e.keys[id] = aead
+	}
+
+	if _, ok := e.keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("активный ключ %s не передан", activeKeyID)
+	}
+
+	return e, nil
+}
+
+// ActiveKeyID Возвращает ID ключа, которым шифруются новые записи
+func (e *TokenEncryptor) ActiveKeyID() string {
+	return e.activeKeyID
+}
+
+// Encrypt Шифрует токен активным ключом и возвращает шифротекст и ID ключа
+func (e *TokenEncryptor) Encrypt(plain string) (string, string, error) {
+	dataKey := make([]byte, tokenDataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", "", err
+	}
+
+	wrappedKey, err := seal(e.keys[e.activeKeyID], dataKey, []byte(e.activeKeyID))
+	if err != nil {
+		return "", "", err
+	}
+
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	sealed, err := seal(dataAEAD, []byte(plain), wrappedKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(append(wrappedKey, sealed...)), e.activeKeyID, nil
+}
+
+// Decrypt Расшифровывает токен ключом keyID. Пустой keyID означает, что токен сохранен без шифрования.
+func (e *TokenEncryptor) Decrypt(encrypted string, keyID string) (string, error) {
+	if keyID == "" {
+		return encrypted, nil
+	}
+
+	keyAEAD, ok := e.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("неизвестный ключ шифрования %s", keyID)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("ошибка расшифровки токена: %v", err)
+	}
+
+	wrappedLen := keyAEAD.NonceSize() + tokenDataKeySize + keyAEAD.Overhead()
+	if len(data) < wrappedLen {
+		return "", errors.New("ошибка расшифровки токена: недостаточная длина")
+	}
+	wrappedKey, sealed := data[:wrappedLen], data[wrappedLen:]
+
+	dataKey, err := open(keyAEAD, wrappedKey, []byte(keyID))
+	if err != nil {
+		return "", fmt.Errorf("ошибка расшифровки ключа токена: %v", err)
+	}
+
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	plain, err := open(dataAEAD, sealed, wrappedKey)
+	if err != nil {
+		return "", fmt.Errorf("ошибка расшифровки токена: %v", err)
+	}
+
+	return string(plain), nil
+}
+
+// NewEncryptedTokenStore Оборачивает хранилище так, что refresh_token хранится зашифрованным
+func NewEncryptedTokenStore(store TokenStore, enc *TokenEncryptor) *EncryptedTokenStore {
+	return &EncryptedTokenStore{store: store, enc: enc}
+}
+
+func (s *EncryptedTokenStore) Get(accountId int) (*AccountToken, error) {
+	token, err := s.store.Get(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, s.decrypt(token)
+}
+
+func (s *EncryptedTokenStore) Save(token *AccountToken) error {
+	encrypted := *token
+
+	var err error
+	encrypted.RefreshToken, encrypted.KeyId, err = s.enc.Encrypt(token.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.Save(&encrypted); err != nil {
+		return err
+	}
+
+	token.Model = encrypted.Model
+
+	return nil
+}
+
+func (s *EncryptedTokenStore) Delete(accountId int) error {
+	return s.store.Delete(accountId)
+}
+
+func (s *EncryptedTokenStore) All() ([]*AccountToken, error) {
+	tokens, err := s.store.All()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range tokens {
+		if err := s.decrypt(t); err != nil {
+			return nil, fmt.Errorf("аккаунт %d: %v", t.AccountId, err)
+		}
+	}
+
+	return tokens, nil
+}
+
+// Rotate Перешифровывает активным ключом все записи, сохраненные другим ключом или без шифрования
+func (s *EncryptedTokenStore) Rotate() error {
+	tokens, err := s.store.All()
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tokens {
+		if t.KeyId == s.enc.ActiveKeyID() {
+			continue
+		}
+
+		if err := s.decrypt(t); err != nil {
+			return fmt.Errorf("аккаунт %d: %v", t.AccountId, err)
+		}
+		if err := s.Save(t); err != nil {
+			return fmt.Errorf("аккаунт %d: %v", t.AccountId, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *EncryptedTokenStore) decrypt(t *AccountToken) error {
+	plain, err := s.enc.Decrypt(t.RefreshToken, t.KeyId)
+	if err != nil {
+		return err
+	}
+
+	t.RefreshToken = plain
+	t.KeyId = ""
+
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal Шифрует данные и возвращает nonce вместе с шифротекстом
+func seal(aead cipher.AEAD, plain, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plain, additional), nil
+}
+
+func open(aead cipher.AEAD, data, additional []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("недостаточная длина")
+	}
+
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], additional)
+}
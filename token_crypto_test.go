@@ -0,0 +1,138 @@
+package amocrm_v4
+
+import (
+	"bytes"
+	"testing"
+)
+
+// memTokenStore Хранилище токенов в памяти, возвращает копии записей, как БД
+type memTokenStore map[int]AccountToken
+
+func (m memTokenStore) Get(accountId int) (*AccountToken, error) {
+	t, ok := m[accountId]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+
+	return &t, nil
+}
+
+func (m memTokenStore) Save(token *AccountToken) error {
+	m[token.AccountId] = *token
+
+	return nil
+}
+
+func (m memTokenStore) Delete(accountId int) error {
+	delete(m, accountId)
+
+	return nil
+}
+
+func (m memTokenStore) All() ([]*AccountToken, error) {
+	var tokens []*AccountToken
+	for _, t := range m {
+		t := t
+		tokens = append(tokens, &t)
+	}
+
+	return tokens, nil
+}
+
+func testEncryptor(t *testing.T, active string, keys map[string][]byte) *TokenEncryptor {
+	t.Helper()
+
+	enc, err := NewTokenEncryptor(active, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return enc
+}
+
+func TestTokenEncryptorRoundTrip(t *testing.T) {
+	enc := testEncryptor(t, "k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+
+	encrypted, keyID, err := enc.Encrypt("refresh-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyID != "k1" {
+		t.Errorf("keyID = %s, want k1", keyID)
+	}
+	if encrypted == "refresh-token" {
+		t.Error("token is not encrypted")
+	}
+
+	plain, err := enc.Decrypt(encrypted, keyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain != "refresh-token" {
+		t.Errorf("Decrypt() = %q, want refresh-token", plain)
+	}
+}
+
+func TestTokenEncryptorWrongKey(t *testing.T) {
+	enc := testEncryptor(t, "k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+	other := testEncryptor(t, "k1", map[string][]byte{"k1": bytes.Repeat([]byte{2}, 32)})
+
+	encrypted, keyID, err := enc.Encrypt("refresh-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := other.Decrypt(encrypted, keyID); err == nil {
+		t.Error("Decrypt() with another key succeeded")
+	}
+	if _, err := enc.Decrypt(encrypted, "k2"); err == nil {
+		t.Error("Decrypt() with unknown key ID succeeded")
+	}
+}
+
+func TestEncryptedTokenStoreRotate(t *testing.T) {
+	keys := map[string][]byte{
+		"k1": bytes.Repeat([]byte{1}, 32),
+		"k2": bytes.Repeat([]byte{2}, 16),
+	}
+	mem := memTokenStore{}
+
+	old := NewEncryptedTokenStore(mem, testEncryptor(t, "k1", keys))
+	if err := old.Save(&AccountToken{AccountId: 1, RefreshToken: "token-1"}); err != nil {
+		t.Fatal(err)
+	}
+	// запись, сохраненная до включения шифрования
+	mem[2] = AccountToken{AccountId: 2, RefreshToken: "token-2"}
+
+	store := NewEncryptedTokenStore(mem, testEncryptor(t, "k2", keys))
+	if err := store.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for id, want := range map[int]string{1: "token-1", 2: "token-2"} {
+		if mem[id].KeyId != "k2" || mem[id].RefreshToken == want {
+			t.Errorf("account %d is not encrypted with k2: %+v", id, mem[id])
+		}
+
+		token, err := store.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.RefreshToken != want {
+			t.Errorf("account %d: RefreshToken = %q, want %q", id, token.RefreshToken, want)
+		}
+	}
+}
+
+func TestEncryptedTokenStoreLegacyPlaintext(t *testing.T) {
+	mem := memTokenStore{1: {AccountId: 1, RefreshToken: "plain-token"}}
+	store := NewEncryptedTokenStore(mem, testEncryptor(t, "k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}))
+
+	token, err := store.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.RefreshToken != "plain-token" {
+		t.Errorf("RefreshToken = %q, want plain-token", token.RefreshToken)
+	}
+}
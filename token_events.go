@@ -0,0 +1,102 @@
+package amocrm_v4
+
+import "time"
+
+type TokenEventType string
+
+const (
+	TokenIssued        TokenEventType = "issued"         // Токены получены по коду авторизации
+	TokenRefreshed     TokenEventType = "refreshed"      // access_token обновлен по refresh_token
+	TokenRefreshFailed TokenEventType = "refresh_failed" // Не удалось обновить access_token
+	TokenExpiringSoon  TokenEventType = "expiring_soon"  // access_token скоро истечет, а обновить его не удалось
+	TokenRevoked       TokenEventType = "revoked"        // amoCRM отклонил refresh_token, обновление остановлено
+	TokenPersistFailed TokenEventType = "persist_failed" // access_token обновлен, но новый refresh_token не удалось сохранить
+)
+
+// TokenEvent Событие жизненного цикла токена аккаунта
+type TokenEvent struct {
+	Type      TokenEventType
+	AccountId int       // ID аккаунта. 0 для клиента NewClient, если аккаунт не запрашивался
+	ExpiresAt time.Time // Время, до которого действует текущий access_token
+	Err       error     // Ошибка для TokenRefreshFailed, TokenRevoked и TokenPersistFailed
+
+	// ConsecutiveFailures Количество неудачных обновлений подряд. Сбрасывается после успешного обновления.
+	ConsecutiveFailures int
+}
+
+// TokenEventCallback Обработчик событий жизненного цикла токена
+type TokenEventCallback func(e TokenEvent)
+
+// OnTokenEvent Регистрирует обработчик событий токена: получение, обновление, ошибки обновления и сохранения,
+// скорое истечение и отзыв. Обработчики вызываются синхронно в горутине, обновляющей токен.
+func (a *Amo) OnTokenEvent(fn TokenEventCallback) {
+	api := a.api()
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	api.onTokenEvent = append(api.onTokenEvent, fn)
+}
+
+// tokenIssued Сохраняет access_token, полученный по коду авторизации
+func (a *authSettings) tokenIssued(accessToken string, expiresAt time.Time) {
+	a.setToken(accessToken, expiresAt)
+	a.emitTokenEvent(TokenEvent{Type: TokenIssued})
+}
+
+// tokenRefreshed Сохраняет обновленный access_token и сбрасывает счетчик ошибок
+func (a *authSettings) tokenRefreshed(accessToken string, expiresAt time.Time) {
+	a.mu.Lock()
+	a.refreshFailures = 0
+	a.mu.Unlock()
+
+	a.setToken(accessToken, expiresAt)
+	a.emitTokenEvent(TokenEvent{Type: TokenRefreshed})
+}
+
+// tokenRefreshFailed Сообщает об ошибке обновления и, если текущий access_token еще действует,
+// один раз на каждый токен сообщает о его скором истечении
+func (a *authSettings) tokenRefreshFailed(err error) {
+	a.mu.Lock()
+	a.refreshFailures++
+	expiring := time.Now().Before(a.expiresAt) && !a.expiringNotified.Equal(a.expiresAt)
+	if expiring {
+		a.expiringNotified = a.expiresAt
+	}
+	a.mu.Unlock()
+
+	a.emitTokenEvent(TokenEvent{Type: TokenRefreshFailed, Err: err})
+
+	if expiring {
+		a.emitTokenEvent(TokenEvent{Type: TokenExpiringSoon, Err: err})
+	}
+}
+
+// tokenPersistFailed Сообщает, что обновленный refresh_token не сохранен в хранилище.
+// Текущий access_token действует, но после перезапуска обновить его будет нечем.
+func (a *authSettings) tokenPersistFailed(err error) {
+	a.emitTokenEvent(TokenEvent{Type: TokenPersistFailed, Err: err})
+}
+
+// tokenRevoked Сообщает об отзыве доступа
+func (a *authSettings) tokenRevoked(err error) {
+	a.mu.Lock()
+	a.refreshFailures++
+	a.mu.Unlock()
+
+	a.emitTokenEvent(TokenEvent{Type: TokenRevoked, Err: err})
+}
+
+// emitTokenEvent Дополняет событие данными аккаунта и вызывает обработчики
+func (a *authSettings) emitTokenEvent(e TokenEvent) {
+	a.mu.RLock()
+	e.AccountId = a.accountId
+	e.ExpiresAt = a.expiresAt
+	e.ConsecutiveFailures = a.refreshFailures
+	callbacks := a.onTokenEvent
+	a.mu.RUnlock()
+
+	for _, fn := range callbacks {
+		fn(e)
+	}
+}
@@ -0,0 +1,442 @@
+package amocrm_v4
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTokenConcurrency   = 5
+	defaultTokenCheckInterval = time.Minute
+	defaultTokenRefreshBefore = 10 * time.Minute
+)
+
+// ErrTokenNotFound Для аккаунта нет сохраненного токена
+var ErrTokenNotFound = errors.New("не найдена запись об авторизации аккаунта")
+
+// AccountToken Запись об авторизации интеграции в одном аккаунте
+type AccountToken struct {
+	gorm.Model
+	AccountId    int       `gorm:"column:account_id;uniqueIndex"`
+	Domain       string    `gorm:"column:domain"` // Адрес аккаунта, например example.amocrm.ru
+	RefreshToken string    `gorm:"column:refresh_token"`
+	ExpiresIn    time.Time `gorm:"column:expires_in"`   // Время, до которого действует access_token
+	Disconnected bool      `gorm:"column:disconnected"` // amoCRM отозвал доступ, токен больше не обновляется
+	KeyId        string    `gorm:"column:key_id"`       // ID ключа шифрования refresh_token, см. EncryptedTokenStore
+}
+
+// Subdomain Возвращает поддомен аккаунта
+func (t *AccountToken) Subdomain() string {
+	return strings.SplitN(t.Domain, ".", 2)[0]
+}
+
+// TokenStore Хранилище токенов аккаунтов
+type TokenStore interface {
+	Get(accountId int) (*AccountToken, error) // Возвращает ErrTokenNotFound, если записи нет
+	Save(token *AccountToken) error           // Создает или обновляет запись по AccountId
+	Delete(accountId int) error
+	All() ([]*AccountToken, error)
+}
+
+// GormTokenStore Хранилище токенов в таблице БД
+type GormTokenStore struct {
+	DB        *gorm.DB
+	TableName string
+}
+
+// TokenManagerConfig Параметры менеджера токенов
+type TokenManagerConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	Store        TokenStore
+
+	Concurrency   int           // Сколько аккаунтов обновляется одновременно. По умолчанию 5
+	CheckInterval time.Duration // Как часто проверять сроки действия токенов. По умолчанию 1 минута
+	RefreshBefore time.Duration // За сколько до истечения обновлять токен. По умолчанию 10 минут
+
+	// OnDisconnect Вызывается, когда интеграция отключена хуком или amoCRM отклонил refresh_token
+	OnDisconnect DisconnectCallback
+	// OnTokenEvent Вызывается при получении, обновлении и ошибках обновления токенов любого аккаунта
+	OnTokenEvent TokenEventCallback
+}
+
+// TokenManager Хранит токены интеграции для множества аккаунтов, обновляет их в фоне
+// и выдает клиентов для конкретных аккаунтов.
+type TokenManager struct {
+	cfg TokenManagerConfig
+
+	mu       sync.Mutex
+	accounts map[int]*authSettings
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewGormTokenStore Создает хранилище токенов в таблице table
+func NewGormTokenStore(db *gorm.DB, table string) *GormTokenStore {
+	return &GormTokenStore{DB: db, TableName: table}
+}
+
+// Migrate Создает или обновляет таблицу токенов
+func (s *GormTokenStore) Migrate() error {
+	return s.DB.Table(s.TableName).AutoMigrate(&AccountToken{})
+}
+
+func (s *GormTokenStore) Get(accountId int) (*AccountToken, error) {
+	token := &AccountToken{}
+
+	result := s.DB.Table(s.TableName).Where("account_id = ?", accountId).Limit(1).Find(token)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, ErrTokenNotFound
+	}
+
+	return token, nil
+}
+
+func (s *GormTokenStore) Save(token *AccountToken) error {
+	_, err := s.Get(token.AccountId)
+	if errors.Is(err, ErrTokenNotFound) {
+		db := s.DB.Table(s.TableName)
+		if token.KeyId == "" {
+			// Таблицы, созданные до появления шифрования, могут не содержать колонку key_id
+			db = db.Omit("key_id")
+		}
+
+		return db.Create(token).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{
+		"domain":        token.Domain,
+		"refresh_token": token.RefreshToken,
+		"expires_in":    token.ExpiresIn,
+		"disconnected":  token.Disconnected,
+	}
+	if token.KeyId != "" {
+		values["key_id"] = token.KeyId
+	}
+
+	return s.DB.Table(s.TableName).Where("account_id = ?", token.AccountId).Updates(values).Error
+}
+
+func (s *GormTokenStore) Delete(accountId int) error {
+	return s.DB.Table(s.TableName).Where("account_id = ?", accountId).Delete(&AccountToken{}).Error
+}
+
+func (s *GormTokenStore) All() ([]*AccountToken, error) {
+	var tokens []*AccountToken
+
+	return tokens, s.DB.Table(s.TableName).Find(&tokens).Error
+}
+
+// NewTokenManager Создает менеджер токенов. Фоновое обновление запускается методом Start.
+func NewTokenManager(cfg TokenManagerConfig) *TokenManager {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaultTokenConcurrency
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = defaultTokenCheckInterval
+	}
+	if cfg.RefreshBefore <= 0 {
+		cfg.RefreshBefore = defaultTokenRefreshBefore
+	}
+
+	return &TokenManager{
+		cfg:      cfg,
+		accounts: make(map[int]*authSettings),
+	}
+}
+
+// Start Запускает фоновое обновление токенов
+func (m *TokenManager) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stop != nil {
+		return
+	}
+
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+
+	go m.refresher(m.stop, m.done)
+}
+
+// Stop Останавливает фоновое обновление и дожидается завершения текущих обновлений
+func (m *TokenManager) Stop() {
+	m.mu.Lock()
+	stop, done := m.stop, m.done
+	m.stop, m.done = nil, nil
+	m.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-done
+}
+
+// Install Сохраняет токены, полученные при установке интеграции (например, в OnSuccess обработчика OAuth),
+// и возвращает клиент для аккаунта. ID аккаунта запрашивается у API.
+func (m *TokenManager) Install(token *OAuthToken) (*Amo, error) {
+	a := m.newAuth(0, token.Referer)
+	a.setToken(token.AccessToken, token.ExpiresAt)
+
+	amo := newAmo(a)
+	acc, err := amo.RefreshAccount()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения аккаунта %s: %v", token.Referer, err)
+	}
+
+	a.mu.Lock()
+	a.accountId = acc.Id
+	a.mu.Unlock()
+
+	err = m.cfg.Store.Save(&AccountToken{
+		AccountId:    acc.Id,
+		Domain:       token.Referer,
+		RefreshToken: token.RefreshToken,
+		ExpiresIn:    token.ExpiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при сохранении токена аккаунта %d: %v", acc.Id, err)
+	}
+
+	m.mu.Lock()
+	m.accounts[acc.Id] = a
+	m.mu.Unlock()
+
+	a.emitTokenEvent(TokenEvent{Type: TokenIssued})
+
+	return amo, nil
+}
+
+// Client Возвращает клиент для аккаунта. Если access_token истек, он обновляется перед возвратом.
+func (m *TokenManager) Client(accountId int) (*Amo, error) {
+	a, err := m.auth(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.isDisconnected() {
+		return nil, ErrAccountDisconnected
+	}
+
+	if !time.Now().Before(a.tokenExpiresAt()) {
+		if err := m.refresh(a, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	return newAmo(a), nil
+}
+
+// Remove Удаляет токены аккаунта из менеджера и хранилища
+func (m *TokenManager) Remove(accountId int) error {
+	m.mu.Lock()
+	delete(m.accounts, accountId)
+	m.mu.Unlock()
+
+	return m.cfg.Store.Delete(accountId)
+}
+
+// RefreshDue Обновляет токены всех аккаунтов, срок действия которых скоро истекает.
+// Одновременно обновляется не больше Concurrency аккаунтов.
+func (m *TokenManager) RefreshDue() error {
+	tokens, err := m.cfg.Store.All()
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(m.cfg.RefreshBefore)
+	sem := make(chan struct{}, m.cfg.Concurrency)
+	var wg sync.WaitGroup
+
+	for _, t := range tokens {
+		if t.Disconnected || t.ExpiresIn.After(deadline) {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(accountId int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			a, err := m.auth(accountId)
+			if err == nil {
+				err = m.refresh(a, m.cfg.RefreshBefore)
+			}
+			if err != nil {
+				log.Errorf("Ошибка при обновлении токена аккаунта %d: %v", accountId, err)
+			}
+		}(t.AccountId)
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+func (m *TokenManager) refresher(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(m.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.RefreshDue(); err != nil {
+			log.Errorf("Ошибка при получении токенов аккаунтов: %v", err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// auth Возвращает настройки аккаунта, загружая их из хранилища при первом обращении
+func (m *TokenManager) auth(accountId int) (*authSettings, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if a, ok := m.accounts[accountId]; ok {
+		return a, nil
+	}
+
+	token, err := m.cfg.Store.Get(accountId)
+	if err != nil {
+		return nil, err
+	}
+	if token.Disconnected {
+		return nil, ErrAccountDisconnected
+	}
+
+	a := m.newAuth(accountId, token.Domain)
+	m.accounts[accountId] = a
+
+	return a, nil
+}
+
+func (m *TokenManager) newAuth(accountId int, domain string) *authSettings {
+	var onTokenEvent []TokenEventCallback
+	if m.cfg.OnTokenEvent != nil {
+		onTokenEvent = []TokenEventCallback{m.cfg.OnTokenEvent}
+	}
+
+	return &authSettings{
+		client:            http.Client{},
+		integrationID:     m.cfg.ClientID,
+		integrationSecret: m.cfg.ClientSecret,
+		endpoint:          "https://" + domain,
+		redirectUri:       m.cfg.RedirectURI,
+		accountId:         accountId,
+		onTokenEvent:      onTokenEvent,
+	}
+}
+
+// refresh Обновляет токен аккаунта, если сохраненный токен истекает раньше, чем через before.
+// Refresh token читается из хранилища, поэтому повторное обновление другим экземпляром не требуется.
+func (m *TokenManager) refresh(a *authSettings, before time.Duration) error {
+	a.refreshMu.Lock()
+	defer a.refreshMu.Unlock()
+
+	token, err := m.cfg.Store.Get(a.accountId)
+	if err != nil {
+		return err
+	}
+
+	// токен мог быть обновлен, пока ждали блокировку
+	if token.ExpiresIn.After(time.Now().Add(before)) && time.Now().Before(a.tokenExpiresAt()) {
+		return nil
+	}
+
+	ret := authResp{}
+	err = a.request(requestOpts{
+		Method: http.MethodPost,
+		Path:   "/oauth2/access_token",
+		DataParameters: &authRequest{
+			ClientId:     a.integrationID,
+			ClientSecret: a.integrationSecret,
+			GrantType:    amoAuthorizationRefreshToken,
+			RefreshToken: token.RefreshToken,
+			RedirectUri:  a.redirectUri,
+		},
+		Ret: &ret,
+	})
+	if IsTokenRevoked(err) {
+		a.tokenRevoked(err)
+
+		token.Disconnected = true
+		if saveErr := m.cfg.Store.Save(token); saveErr != nil {
+			log.Errorf("Ошибка при сохранении отключения аккаунта %d: %v", a.accountId, saveErr)
+		}
+
+		e := DisconnectEvent{AccountId: a.accountId, Reason: DisconnectTokenRevoked, Err: err}
+		if disconnectErr := m.disconnect(e, false); disconnectErr != nil {
+			log.Errorf("Ошибка при отключении аккаунта %d: %v", a.accountId, disconnectErr)
+		}
+
+		return fmt.Errorf("%w: %v", ErrAccountDisconnected, err)
+	}
+	if err != nil {
+		a.tokenRefreshFailed(err)
+		return fmt.Errorf("ошибка получения нового access_token аккаунта %d: %w", a.accountId, err)
+	}
+
+	exprIn := time.Now().Add(time.Duration(ret.ExpiresIn)*time.Second - 1*time.Minute)
+	token.RefreshToken = ret.RefreshToken
+	token.ExpiresIn = exprIn
+
+	a.tokenRefreshed(ret.AccessToken, exprIn)
+
+	if err := m.cfg.Store.Save(token); err != nil {
+		err = fmt.Errorf("ошибка при сохранении токена аккаунта %d: %w", a.accountId, err)
+		a.tokenPersistFailed(err)
+		return err
+	}
+
+	return nil
+}
+
+// disconnect Отключает аккаунт: останавливает выдачу клиентов и вызывает OnDisconnect.
+// При remove токен удаляется из хранилища.
+func (m *TokenManager) disconnect(e DisconnectEvent, remove bool) error {
+	m.mu.Lock()
+	a, ok := m.accounts[e.AccountId]
+	delete(m.accounts, e.AccountId)
+	m.mu.Unlock()
+
+	if ok {
+		a.disconnect(e)
+	}
+
+	if remove {
+		if err := m.cfg.Store.Delete(e.AccountId); err != nil {
+			return err
+		}
+	}
+
+	if m.cfg.OnDisconnect != nil {
+		m.cfg.OnDisconnect(e)
+	}
+
+	return nil
+}
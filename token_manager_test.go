@@ -0,0 +1,59 @@
+package amocrm_v4
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	dummy "gorm.io/gorm/utils/tests"
+)
+
+// dryRunTokenStore Возвращает хранилище без БД и SQL последнего запроса на запись.
+// exists имитирует наличие записи аккаунта в таблице.
+func dryRunTokenStore(t *testing.T, exists bool) (*GormTokenStore, *string) {
+	t.Helper()
+
+	db, err := gorm.Open(dummy.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+
+	var sql string
+	capture := func(db *gorm.DB) { sql = db.Statement.SQL.String() }
+	_ = db.Callback().Create().After("gorm:create").Register("test:capture", capture)
+	_ = db.Callback().Update().After("gorm:update").Register("test:capture", capture)
+	_ = db.Callback().Query().After("gorm:query").Register("test:exists", func(db *gorm.DB) {
+		if exists {
+			db.RowsAffected = 1
+		}
+	})
+
+	return NewGormTokenStore(db, "tokens"), &sql
+}
+
+func TestGormTokenStoreSaveKeyId(t *testing.T) {
+	tests := []struct {
+		name   string
+		exists bool
+		keyId  string
+		want   bool
+	}{
+		{"create plaintext", false, "", false},
+		{"create encrypted", false, "k1", true},
+		{"update plaintext", true, "", false},
+		{"update encrypted", true, "k1", true},
+	}
+
+	for _, tt := range tests {
+		store, sql := dryRunTokenStore(t, tt.exists)
+
+		if err := store.Save(&AccountToken{AccountId: 1, RefreshToken: "token", KeyId: tt.keyId}); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := strings.Contains(*sql, "key_id"); got != tt.want {
+			t.Errorf("%s: key_id in %q = %v, want %v", tt.name, *sql, got, tt.want)
+		}
+	}
+}
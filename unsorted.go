@@ -0,0 +1,368 @@
+package amocrm_v4
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type (
+	Uns                  struct{ service }
+	UnsortedCategoryType string
+	UnsortedList         []*unsorted
+)
+
+const (
+	UnsortedSip   UnsortedCategoryType = "sip"
+	UnsortedForms UnsortedCategoryType = "forms"
+	UnsortedChats UnsortedCategoryType = "chats"
+	UnsortedMail  UnsortedCategoryType = "mail"
+)
+
+type GetUnsortedQueryParams struct {
+	Page               int                    `url:"page,omitempty"`                // Страница выборки
+	Limit              int                    `url:"limit,omitempty"`               // Количество возвращаемых сущностей за один запрос (Максимум – 250)
+	FilterByUid        []string               `url:"filter[uid][],omitempty"`       // Фильтр по UID неразобранного
+	FilterByCategory   []UnsortedCategoryType `url:"filter[category][],omitempty"`  // Фильтр по категории неразобранного
+	FilterByPipelineId int                    `url:"filter[pipeline_id],omitempty"` // Фильтр по ID воронки
+	OrderByCreatedAt   OrderDirectionType     `url:"order[created_at],omitempty"`   // Сортировка по дате создания
+	OrderByUpdatedAt   OrderDirectionType     `url:"order[updated_at],omitempty"`   // Сортировка по дате изменения
+	OrderById          OrderDirectionType     `url:"order[id],omitempty"`           // Сортировка по ID
+}
+
+type GetUnsortedSummaryQueryParams struct {
+	FilterByUid           []string               `url:"filter[uid][],omitempty"`
+	FilterByCreatedAtFrom int                    `url:"filter[created_at][from],omitempty"`
+	FilterByCreatedAtTo   int                    `url:"filter[created_at][to],omitempty"`
+	FilterByPipelineId    int                    `url:"filter[pipeline_id],omitempty"`
+	FilterByCategory      []UnsortedCategoryType `url:"filter[category][],omitempty"`
+}
+
+type unsorted struct {
+	Uid        string               `json:"uid,omitempty"`         // UID неразобранного
+	SourceUid  string               `json:"source_uid,omitempty"`  // Уникальный идентификатор источника заявки
+	SourceName string               `json:"source_name,omitempty"` // Название источника заявки
+	Category   UnsortedCategoryType `json:"category,omitempty"`    // Категория неразобранного
+	PipelineId int                  `json:"pipeline_id,omitempty"` // ID воронки, в которую добавляется неразобранное
+	CreatedAt  int                  `json:"created_at,omitempty"`  // Дата создания неразобранного, передается в Unix Timestamp
+	// Metadata Метаданные заявки: *UnsortedSipMetadata, *UnsortedFormsMetadata или *UnsortedChatsMetadata
+	Metadata  interface{}      `json:"metadata,omitempty"`
+	AccountId int              `json:"account_id,omitempty"`
+	RequestId string           `json:"request_id,omitempty"`
+	Embedded  unsortedEmbedded `json:"_embedded,omitempty"`
+}
+
+type unsortedEmbedded struct {
+	Leads     []*lead    `json:"leads,omitempty"`
+	Contacts  []*contact `json:"contacts,omitempty"`
+	Companies []*company `json:"companies,omitempty"`
+}
+
+// MarshalJSON Передает контакты только с заполненными полями
+func (e unsortedEmbedded) MarshalJSON() ([]byte, error) {
+	type embedded unsortedEmbedded
+
+	return json.Marshal(struct {
+		embedded
+		Contacts []complexContact `json:"contacts,omitempty"`
+	}{embedded: embedded(e), Contacts: complexContacts(e.Contacts)})
+}
+
+// UnsortedSipMetadata Метаданные неразобранного типа sip (звонок)
+type UnsortedSipMetadata struct {
+	From              string `json:"from"`                           // Кто звонил
+	Phone             string `json:"phone"`                          // Номер телефона, на который поступил звонок
+	CalledAt          int    `json:"called_at"`                      // Когда был совершен звонок в Unix Timestamp
+	Duration          int    `json:"duration"`                       // Сколько длился звонок в секундах
+	Link              string `json:"link,omitempty"`                 // Ссылка на запись звонка
+	ServiceCode       string `json:"service_code"`                   // Код сервиса, через который был совершен звонок
+	IsCallEventNeeded bool   `json:"is_call_event_needed,omitempty"` // Должно ли при создании неразобранного появиться уведомление о звонке
+	Uniq              string `json:"uniq,omitempty"`                 // Уникальный идентификатор звонка
+}
+
+// UnsortedFormsMetadata Метаданные неразобранного типа forms (заявка с формы)
+type UnsortedFormsMetadata struct {
+	FormId     string `json:"form_id"`               // Идентификатор формы на стороне интеграции
+	FormName   string `json:"form_name"`             // Название формы
+	FormPage   string `json:"form_page"`             // Страница, на которой установлена форма
+	Ip         string `json:"ip"`                    // IP адрес, с которого поступила заявка
+	FormSentAt int    `json:"form_sent_at"`          // Время отправки данных через форму в Unix Timestamp
+	Referer    string `json:"referer,omitempty"`     // Откуда был переход на страницу с формой
+	VisitorUid string `json:"visitor_uid,omitempty"` // Идентификатор посетителя
+}
+
+// UnsortedChatsMetadata Метаданные неразобранного типа chats
+type UnsortedChatsMetadata struct {
+	From       string `json:"from"`
+	To         string `json:"to,omitempty"`
+	ReceivedAt int    `json:"received_at"`
+	Service    string `json:"service"`
+	Client     struct {
+		Name   string `json:"name"`
+		Avatar string `json:"avatar,omitempty"`
+	} `json:"client"`
+	Origin          string `json:"origin"`
+	LastMessageText string `json:"last_message_text"`
+	SourceName      string `json:"source_name"`
+}
+
+type allUnsorted struct {
+	Page       int   `json:"_page"`
+	TotalItems int   `json:"_total_items,omitempty"`
+	Links      links `json:"_links"`
+	Embedded   struct {
+		Unsorted []*unsorted `json:"unsorted"`
+	} `json:"_embedded"`
+}
+
+// unsortedActionResult Ответ на принятие, отклонение и привязку неразобранного
+type unsortedActionResult struct {
+	Uid       string               `json:"uid"`
+	Category  UnsortedCategoryType `json:"category"`
+	AccountId int                  `json:"account_id,omitempty"`
+	Embedded  struct {
+		Leads []struct {
+			Id int `json:"id"`
+		} `json:"leads"`
+		Contacts []struct {
+			Id int `json:"id"`
+		} `json:"contacts"`
+		Companies []struct {
+			Id int `json:"id"`
+		} `json:"companies"`
+	} `json:"_embedded"`
+}
+
+type unsortedSummary struct {
+	Total           int `json:"total"`             // Общее количество неразобранного
+	Accepted        int `json:"accepted"`          // Количество принятых заявок
+	Declined        int `json:"declined"`          // Количество отклоненных заявок
+	AverageSortTime int `json:"average_sort_time"` // Среднее время разбора в секундах
+	Categories      struct {
+		Forms int `json:"forms"`
+		Sip   int `json:"sip"`
+		Chats int `json:"chats"`
+		Mail  int `json:"mail"`
+	} `json:"categories"`
+}
+
+type unsortedAcceptRequest struct {
+	UserId   int `json:"user_id,omitempty"`   // ID пользователя, от имени которого принимается неразобранное
+	StatusId int `json:"status_id,omitempty"` // ID статуса, в который будет перемещена сделка
+}
+
+type unsortedDeclineRequest struct {
+	UserId int `json:"user_id,omitempty"` // ID пользователя, от имени которого отклоняется неразобранное
+}
+
+type unsortedLinkRequest struct {
+	Link struct {
+		EntityId   int    `json:"entity_id"`   // ID сделки, к которой привязывается неразобранное
+		EntityType string `json:"entity_type"` // Тип сущности, поддерживается только leads
+	} `json:"link"`
+	UserId int `json:"user_id,omitempty"`
+}
+
+func (u *unsorted) UnmarshalJSON(data []byte) error {
+	type plain unsorted
+
+	var raw struct {
+		plain
+		Metadata json.RawMessage `json:"metadata"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*u = unsorted(raw.plain)
+	u.Metadata = nil
+
+	if len(raw.Metadata) == 0 || string(raw.Metadata) == "null" {
+		return nil
+	}
+
+	var meta interface{}
+	switch u.Category {
+	case UnsortedSip:
+		meta = &UnsortedSipMetadata{}
+	case UnsortedForms:
+		meta = &UnsortedFormsMetadata{}
+	case UnsortedChats:
+		meta = &UnsortedChatsMetadata{}
+	default:
+		meta = &map[string]interface{}{}
+	}
+
+	if err := json.Unmarshal(raw.Metadata, meta); err != nil {
+		return fmt.Errorf("ошибка разбора метаданных неразобранного %s: %v", u.Uid, err)
+	}
+
+	u.Metadata = meta
+
+	return nil
+}
+
+// NewSip Создает неразобранное типа sip
+func (un Uns) NewSip(meta *UnsortedSipMetadata) *unsorted {
+	return &unsorted{Metadata: meta}
+}
+
+// NewForm Создает неразобранное типа forms
+func (un Uns) NewForm(meta *UnsortedFormsMetadata) *unsorted {
+	return &unsorted{Metadata: meta}
+}
+
+// All Метод позволяет получить список всех неразобранных заявок.
+func (un Uns) All() (UnsortedList, error) {
+	return un.multiplyRequest(&GetUnsortedQueryParams{
+		Limit: 250,
+	})
+}
+
+// Query Метод позволяет получить список неразобранных заявок по фильтру.
+func (un Uns) Query(params *GetUnsortedQueryParams) (UnsortedList, error) {
+	if params.Limit == 0 {
+		params.Limit = 250
+	}
+
+	return un.multiplyRequest(params)
+}
+
+// ByUID Метод позволяет получить неразобранное по UID.
+func (un Uns) ByUID(uid string) (*unsorted, error) {
+	ret := unsorted{}
+
+	err := un.api().request(requestOpts{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("/api/v4/leads/unsorted/%s", uid),
+		Ret:    &ret,
+	})
+
+	ret.bind(un.service)
+
+	return &ret, err
+}
+
+// bind Привязывает вложенные сделки, контакты и компании неразобранного к аккаунту
+func (u *unsorted) bind(s service) {
+	Leads(u.Embedded.Leads).bind(s)
+	for _, ct := range u.Embedded.Contacts {
+		ct.bind(s)
+	}
+	for _, cmp := range u.Embedded.Companies {
+		cmp.service = s
+	}
+}
+
+// CreateSip Метод позволяет добавлять неразобранное типа sip пакетно.
+func (un Uns) CreateSip(items UnsortedList) (*allUnsorted, error) {
+	return un.create(UnsortedSip, items)
+}
+
+// CreateForms Метод позволяет добавлять неразобранное типа forms пакетно.
+func (un Uns) CreateForms(items UnsortedList) (*allUnsorted, error) {
+	return un.create(UnsortedForms, items)
+}
+
+// Accept Метод позволяет принять неразобранное. Сделка и контакт переходят в обычный статус.
+func (un Uns) Accept(uid string, userId int, statusId int) (*unsortedActionResult, error) {
+	ret := unsortedActionResult{}
+
+	return &ret, un.api().request(requestOpts{
+		Method:         http.MethodPost,
+		Path:           fmt.Sprintf("/api/v4/leads/unsorted/%s/accept", uid),
+		DataParameters: &unsortedAcceptRequest{UserId: userId, StatusId: statusId},
+		Ret:            &ret,
+	})
+}
+
+// Decline Метод позволяет отклонить неразобранное. Сущности из заявки будут удалены.
+func (un Uns) Decline(uid string, userId int) (*unsortedActionResult, error) {
+	ret := unsortedActionResult{}
+
+	return &ret, un.api().request(requestOpts{
+		Method:         http.MethodDelete,
+		Path:           fmt.Sprintf("/api/v4/leads/unsorted/%s/decline", uid),
+		DataParameters: &unsortedDeclineRequest{UserId: userId},
+		Ret:            &ret,
+	})
+}
+
+// Link Метод позволяет привязать неразобранное (только категории chats) к существующей сделке.
+func (un Uns) Link(uid string, leadId int, userId int) (*unsortedActionResult, error) {
+	req := unsortedLinkRequest{UserId: userId}
+	req.Link.EntityId = leadId
+	req.Link.EntityType = "leads"
+
+	ret := unsortedActionResult{}
+
+	return &ret, un.api().request(requestOpts{
+		Method:         http.MethodPost,
+		Path:           fmt.Sprintf("/api/v4/leads/unsorted/%s/link", uid),
+		DataParameters: &req,
+		Ret:            &ret,
+	})
+}
+
+// Summary Метод позволяет получить сводную информацию о неразобранном.
+func (un Uns) Summary(params *GetUnsortedSummaryQueryParams) (*unsortedSummary, error) {
+	ret := unsortedSummary{}
+
+	return &ret, un.api().request(requestOpts{
+		Method:        http.MethodGet,
+		Path:          "/api/v4/leads/unsorted/summary",
+		URLParameters: params,
+		Ret:           &ret,
+	})
+}
+
+func (un Uns) create(category UnsortedCategoryType, items UnsortedList) (*allUnsorted, error) {
+	ret := allUnsorted{}
+
+	err := un.api().request(requestOpts{
+		Method:         http.MethodPost,
+		Path:           fmt.Sprintf("/api/v4/leads/unsorted/%s", category),
+		DataParameters: &items,
+		Ret:            &ret,
+	})
+
+	for _, u := range ret.Embedded.Unsorted {
+		u.bind(un.service)
+	}
+
+	return &ret, err
+}
+
+func (un Uns) multiplyRequest(params *GetUnsortedQueryParams) (UnsortedList, error) {
+	var items UnsortedList
+
+	path := "/api/v4/leads/unsorted"
+
+	for {
+		var tmpItems allUnsorted
+
+		err := un.api().request(requestOpts{
+			Method:        http.MethodGet,
+			Path:          path,
+			URLParameters: &params,
+			Ret:           &tmpItems,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, u := range tmpItems.Embedded.Unsorted {
+			u.bind(un.service)
+		}
+		items = append(items, tmpItems.Embedded.Unsorted...)
+
+		if len(tmpItems.Links.Next.Href) > 0 {
+			params.Page = tmpItems.Page + 1
+		} else {
+			break
+		}
+	}
+
+	return items, nil
+}
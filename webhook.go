@@ -0,0 +1,228 @@
+package amocrm_v4
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+type (
+	Wh             struct{ service }
+	WebhookSetting string
+	Webhooks       []*webhook
+)
+
+const (
+	WebhookAddLead         WebhookSetting = "add_lead"         // Добавлена сделка
+	WebhookUpdateLead      WebhookSetting = "update_lead"      // Сделка изменена
+	WebhookDeleteLead      WebhookSetting = "delete_lead"      // Удалена сделка
+	WebhookRestoreLead     WebhookSetting = "restore_lead"     // Сделка восстановлена из удаленных
+	WebhookStatusLead      WebhookSetting = "status_lead"      // У сделки сменился статус
+	WebhookResponsibleLead WebhookSetting = "responsible_lead" // У сделки сменился ответственный
+	WebhookNoteLead        WebhookSetting = "note_lead"        // Примечание добавлено в сделку
+
+	WebhookAddContact         WebhookSetting = "add_contact"         // Добавлен контакт
+	WebhookUpdateContact      WebhookSetting = "update_contact"      // Контакт изменен
+	WebhookDeleteContact      WebhookSetting = "delete_contact"      // Удален контакт
+	WebhookRestoreContact     WebhookSetting = "restore_contact"     // Контакт восстановлен из удаленных
+	WebhookResponsibleContact WebhookSetting = "responsible_contact" // У контакта сменился ответственный
+	WebhookNoteContact        WebhookSetting = "note_contact"        // Примечание добавлено в контакт
+
+	WebhookAddCompany         WebhookSetting = "add_company"         // Добавлена компания
+	WebhookUpdateCompany      WebhookSetting = "update_company"      // Компания изменена
+	WebhookDeleteCompany      WebhookSetting = "delete_company"      // Удалена компания
+	WebhookRestoreCompany     WebhookSetting = "restore_company"     // Компания восстановлена из удаленных
+	WebhookResponsibleCompany WebhookSetting = "responsible_company" // У компании сменился ответственный
+	WebhookNoteCompany        WebhookSetting = "note_company"        // Примечание добавлено в компанию
+
+	WebhookAddCustomer         WebhookSetting = "add_customer"         // Добавлен покупатель
+	WebhookUpdateCustomer      WebhookSetting = "update_customer"      // Покупатель изменен
+	WebhookDeleteCustomer      WebhookSetting = "delete_customer"      // Удален покупатель
+	WebhookResponsibleCustomer WebhookSetting = "responsible_customer" // У покупателя сменился ответственный
+	WebhookNoteCustomer        WebhookSetting = "note_customer"        // Примечание добавлено в покупателя
+
+	WebhookAddTask         WebhookSetting = "add_task"         // Добавлена задача
+	WebhookUpdateTask      WebhookSetting = "update_task"      // Задача изменена
+	WebhookDeleteTask      WebhookSetting = "delete_task"      // Удалена задача
+	WebhookResponsibleTask WebhookSetting = "responsible_task" // У задачи сменился ответственный
+
+	WebhookAddTalk    WebhookSetting = "add_talk"    // Добавлена беседа
+	WebhookUpdateTalk WebhookSetting = "update_talk" // Беседа изменена
+)
+
+type webhook struct {
+	Id          int              `json:"id,omitempty"`
+	Destination string           `json:"destination"`          // URL, на который будут приходить вебхуки
+	CreatedAt   int              `json:"created_at,omitempty"` // Дата создания подписки
+	UpdatedAt   int              `json:"updated_at,omitempty"` // Дата изменения подписки
+	AccountId   int              `json:"account_id,omitempty"`
+	CreatedBy   int              `json:"created_by,omitempty"`
+	Sort        int              `json:"sort,omitempty"`     // Порядок вызова вебхуков
+	Disabled    bool             `json:"disabled,omitempty"` // Отключен ли вебхук
+	Settings    []WebhookSetting `json:"settings"`           // События, на которые подписан вебхук
+}
+
+type allWebhooks struct {
+	TotalItems int `json:"_total_items"`
+	Embedded   struct {
+		Webhooks Webhooks `json:"webhooks"`
+	} `json:"_embedded"`
+}
+
+type GetWebhooksQueryParams struct {
+	FilterByDestination string `url:"filter[destination],omitempty"` // Фильтр по точному адресу вебхука
+}
+
+// WebhookReconcileResult Итог приведения подписок аккаунта к желаемому набору
+type WebhookReconcileResult struct {
+	Created   []string // Адреса, на которые оформлена новая подписка
+	Updated   []string // Адреса, у которых изменен набор событий
+	Deleted   []string // Адреса, подписка на которые удалена
+	Unchanged []string // Адреса, подписка на которые уже соответствует желаемой
+}
+
+// New Возвращает описание подписки для Subscribe или Reconcile
+func (w Wh) New(destination string, settings ...WebhookSetting) *webhook {
+	return &webhook{Destination: destination, Settings: settings}
+}
+
+// All Метод возвращает все подписки на вебхуки аккаунта.
+func (w Wh) All() (Webhooks, error) {
+	return w.Query(&GetWebhooksQueryParams{})
+}
+
+// Query Метод возвращает подписки на вебхуки по фильтру.
+func (w Wh) Query(params *GetWebhooksQueryParams) (Webhooks, error) {
+	ret := allWebhooks{}
+
+	err := w.api().request(requestOpts{
+		Method:        http.MethodGet,
+		Path:          "/api/v4/webhooks",
+		URLParameters: params,
+		Ret:           &ret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ret.Embedded.Webhooks, nil
+}
+
+// Subscribe Метод подписывает адрес на события. Если адрес уже подписан,
+// набор событий будет заменен переданным.
+func (w Wh) Subscribe(destination string, settings ...WebhookSetting) (*webhook, error) {
+	if destination == "" {
+		return nil, fmt.Errorf("не указан адрес вебхука")
+	}
+	if len(settings) == 0 {
+		return nil, fmt.Errorf("не указаны события для вебхука %s", destination)
+	}
+
+	req := webhook{Destination: destination, Settings: settings}
+	ret := webhook{}
+
+	return &ret, w.api().request(requestOpts{
+		Method:         http.MethodPost,
+		Path:           "/api/v4/webhooks",
+		DataParameters: &req,
+		Ret:            &ret,
+	})
+}
+
+// Unsubscribe Метод отписывает адрес от всех событий.
+func (w Wh) Unsubscribe(destination string) error {
+	return w.api().request(requestOpts{
+		Method:         http.MethodDelete,
+		Path:           "/api/v4/webhooks",
+		DataParameters: &webhook{Destination: destination},
+	})
+}
+
+// Reconcile Метод приводит подписки аккаунта к желаемому набору: подписывает недостающие адреса
+// и обновляет события у существующих. При prune подписки на адреса, отсутствующие в desired, удаляются.
+func (w Wh) Reconcile(desired Webhooks, prune bool) (*WebhookReconcileResult, error) {
+	existing, err := w.All()
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]*webhook, len(existing))
+	for _, wh := range existing {
+		current[wh.Destination] = wh
+	}
+
+	ret := &WebhookReconcileResult{}
+	wanted := make(map[string]bool, len(desired))
+
+	for _, d := range desired {
+		if wanted[d.Destination] {
+			return ret, fmt.Errorf("адрес вебхука %s указан несколько раз", d.Destination)
+		}
+		wanted[d.Destination] = true
+
+		cur, ok := current[d.Destination]
+		if ok && !cur.Disabled && sameWebhookSettings(cur.Settings, d.Settings) {
+			ret.Unchanged = append(ret.Unchanged, d.Destination)
+			continue
+		}
+
+		if _, err := w.Subscribe(d.Destination, d.Settings...); err != nil {
+			return ret, err
+		}
+
+		if ok {
+			ret.Updated = append(ret.Updated, d.Destination)
+		} else {
+			ret.Created = append(ret.Created, d.Destination)
+		}
+	}
+
+	if prune {
+		for _, wh := range existing {
+			if wanted[wh.Destination] {
+				continue
+			}
+
+			if err := w.Unsubscribe(wh.Destination); err != nil {
+				return ret, err
+			}
+			ret.Deleted = append(ret.Deleted, wh.Destination)
+		}
+	}
+
+	return ret, nil
+}
+
+// Has Проверяет, подписан ли вебхук на событие
+func (wh *webhook) Has(setting WebhookSetting) bool {
+	for _, s := range wh.Settings {
+		if s == setting {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sameWebhookSettings(a, b []WebhookSetting) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	x := make([]string, 0, len(a))
+	y := make([]string, 0, len(b))
+	for i := range a {
+		x = append(x, string(a[i]))
+		y = append(y, string(b[i]))
+	}
+	sort.Strings(x)
+	sort.Strings(y)
+
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+
+	return true
+}
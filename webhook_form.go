@@ -0,0 +1,177 @@
+package amocrm_v4
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// formNode Узел дерева, построенного из ключей вида leads[status][0][custom_fields][0][values][0][value]
+type formNode struct {
+	value    string
+	children map[string]*formNode
+}
+
+// parseWebhookForm Строит дерево из плоских ключей x-www-form-urlencoded
+func parseWebhookForm(values url.Values) *formNode {
+	root := &formNode{}
+
+	for key, vals := range values {
+		if len(vals) == 0 {
+			continue
+		}
+
+		n := root
+		for _, part := range splitFormKey(key) {
+			n = n.child(part)
+		}
+		n.value = vals[0]
+	}
+
+	return root
+}
+
+// splitFormKey Разбивает ключ a[b][0][c] на части a, b, 0, c
+func splitFormKey(key string) []string {
+	i := strings.IndexByte(key, '[')
+	if i < 0 {
+		return []string{key}
+	}
+
+	parts := []string{key[:i]}
+	rest := key[i:]
+	for len(rest) > 0 && rest[0] == '[' {
+		end := strings.IndexByte(rest, ']')
+		if end < 0 {
+			break
+		}
+		parts = append(parts, rest[1:end])
+		rest = rest[end+1:]
+	}
+
+	return parts
+}
+
+func (n *formNode) child(key string) *formNode {
+	if n.children == nil {
+		n.children = make(map[string]*formNode)
+	}
+
+	c, ok := n.children[key]
+	if !ok {
+		c = &formNode{}
+		n.children[key] = c
+	}
+
+	return c
+}
+
+// get Возвращает вложенный узел по пути или nil
+func (n *formNode) get(path ...string) *formNode {
+	for _, p := range path {
+		if n == nil || n.children == nil {
+			return nil
+		}
+		n = n.children[p]
+	}
+
+	return n
+}
+
+// keys Возвращает ключи дочерних узлов в порядке следования
+func (n *formNode) keys() []string {
+	if n == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(n.children))
+	for k := range n.children {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.Atoi(keys[i])
+		b, errB := strconv.Atoi(keys[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+
+		return keys[i] < keys[j]
+	})
+
+	return keys
+}
+
+// list Возвращает дочерние узлы по пути, упорядоченные по индексу
+func (n *formNode) list(path ...string) []*formNode {
+	parent := n.get(path...)
+
+	var ret []*formNode
+	for _, k := range parent.keys() {
+		ret = append(ret, parent.children[k])
+	}
+
+	return ret
+}
+
+func (n *formNode) str(path ...string) string {
+	c := n.get(path...)
+	if c == nil {
+		return ""
+	}
+
+	return c.value
+}
+
+func (n *formNode) num(path ...string) int {
+	v, _ := strconv.Atoi(n.str(path...))
+
+	return v
+}
+
+func (n *formNode) flag(path ...string) bool {
+	v := n.str(path...)
+
+	return v == "1" || v == "true"
+}
+
+// customFields Переводит custom_fields вебхука в значения полей модели
+func (n *formNode) customFields() CustomFields {
+	var ret CustomFields
+
+	for _, f := range n.list("custom_fields") {
+		cf := CustomField{
+			FieldId:   f.num("id"),
+			FieldName: f.str("name"),
+		}
+		if code := f.str("code"); code != "" {
+			cf.FieldCode = &code
+		}
+
+		for _, v := range f.list("values") {
+			value := CustomFieldValue{EnumId: v.num("enum")}
+			if v.get("value") != nil {
+				value.Value = v.str("value")
+			} else if v.children == nil {
+				value.Value = v.value
+			}
+			cf.Values = append(cf.Values, value)
+		}
+
+		ret = append(ret, cf)
+	}
+
+	return ret
+}
+
+// tags Переводит теги вебхука в теги модели
+func (n *formNode) tags() []Tag {
+	var ret []Tag
+
+	for _, t := range n.list("tags") {
+		ret = append(ret, Tag{Id: t.num("id"), Name: t.str("name")})
+	}
+
+	return ret
+}
@@ -0,0 +1,409 @@
+package amocrm_v4
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// webhookMaxBody Максимальный размер тела входящего вебхука
+	webhookMaxBody = 1 << 20
+
+	defaultWebhookWorkers   = 4
+	defaultWebhookQueueSize = 1000
+)
+
+// WebhookAccount Аккаунт, от которого пришел вебхук
+type WebhookAccount struct {
+	Id        int
+	Subdomain string
+}
+
+// WebhookEvent Событие из входящего вебхука. Заполнена только сущность, к которой относится событие.
+type WebhookEvent struct {
+	Type          WebhookSetting
+	Account       WebhookAccount
+	Lead          *lead
+	OldStatusId   int // Для status_lead: статус сделки до изменения
+	OldPipelineId int // Для status_lead: воронка сделки до изменения
+	Contact       *contact
+	Company       *company
+	Task          *task
+	Note          *note
+}
+
+// Bind Привязывает сущности события к клиенту аккаунта, чтобы через них можно было выполнять запросы.
+// Без привязки используется клиент NewClient. При работе через TokenManager передайте клиент аккаунта события.
+func (e *WebhookEvent) Bind(amo *Amo) *WebhookEvent {
+	if e.Lead != nil {
+		e.Lead.service = amo.service
+	}
+	if e.Contact != nil {
+		e.Contact.bind(amo.service)
+	}
+	if e.Company != nil {
+		e.Company.service = amo.service
+	}
+	if e.Task != nil {
+		e.Task.service = amo.service
+	}
+	if e.Note != nil {
+		e.Note.service = amo.service
+	}
+
+	return e
+}
+
+// WebhookCallback Обработчик события вебхука
+type WebhookCallback func(e *WebhookEvent)
+
+// WebhookHandler http.Handler для приема вебхуков amoCRM. Отвечает 200 сразу после разбора запроса,
+// обработчики вызываются асинхронно пулом воркеров. Если очередь заполнена, отвечает 503,
+// и amoCRM повторит отправку позже.
+type WebhookHandler struct {
+	mu        sync.RWMutex
+	callbacks map[WebhookSetting][]WebhookCallback
+	all       []WebhookCallback
+	queue     chan []*WebhookEvent
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// NewWebhookHandler Создает обработчик вебхуков с workers воркерами и очередью на queueSize запросов.
+// Нулевые значения заменяются значениями по умолчанию.
+func NewWebhookHandler(workers, queueSize int) *WebhookHandler {
+	if workers <= 0 {
+		workers = defaultWebhookWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultWebhookQueueSize
+	}
+
+	h := &WebhookHandler{
+		callbacks: make(map[WebhookSetting][]WebhookCallback),
+		queue:     make(chan []*WebhookEvent, queueSize),
+	}
+
+	h.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go h.worker()
+	}
+
+	return h
+}
+
+// On Регистрирует обработчик для типа события
+func (h *WebhookHandler) On(t WebhookSetting, fn WebhookCallback) *WebhookHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.callbacks[t] = append(h.callbacks[t], fn)
+
+	return h
+}
+
+// OnAny Регистрирует обработчик для всех событий
+func (h *WebhookHandler) OnAny(fn WebhookCallback) *WebhookHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.all = append(h.all, fn)
+
+	return h
+}
+
+// Close Прекращает прием вебхуков и дожидается обработки уже принятых
+func (h *WebhookHandler) Close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	close(h.queue)
+	h.mu.Unlock()
+
+	h.wg.Wait()
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, webhookMaxBody)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	events := ParseWebhookEvents(r.PostForm)
+	if len(events) == 0 {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.closed {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	select {
+	case h.queue <- events:
+		w.WriteHeader(http.StatusOK)
+	default:
+		log.Warnf("очередь вебхуков заполнена, отброшено событий: %d", len(events))
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	}
+}
+
+func (h *WebhookHandler) worker() {
+	defer h.wg.Done()
+
+	for events := range h.queue {
+		for _, e := range events {
+			h.dispatch(e)
+		}
+	}
+}
+
+func (h *WebhookHandler) dispatch(e *WebhookEvent) {
+	h.mu.RLock()
+	callbacks := make([]WebhookCallback, 0, len(h.callbacks[e.Type])+len(h.all))
+	callbacks = append(callbacks, h.callbacks[e.Type]...)
+	callbacks = append(callbacks, h.all...)
+	h.mu.RUnlock()
+
+	for _, fn := range callbacks {
+		h.call(fn, e)
+	}
+}
+
+func (h *WebhookHandler) call(fn WebhookCallback, e *WebhookEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("паника в обработчике вебхука %s: %v", e.Type, r)
+		}
+	}()
+
+	fn(e)
+}
+
+// ParseWebhookEvents Разбирает тело вебхука amoCRM в список событий
+func ParseWebhookEvents(values url.Values) []*WebhookEvent {
+	root := parseWebhookForm(values)
+
+	account := WebhookAccount{
+		Id:        root.num("account", "id"),
+		Subdomain: root.str("account", "subdomain"),
+	}
+
+	var events []*WebhookEvent
+	add := func(t WebhookSetting, fill func(e *WebhookEvent)) {
+		e := &WebhookEvent{Type: t, Account: account}
+		fill(e)
+		events = append(events, e)
+	}
+
+	for _, action := range root.get("leads").keys() {
+		for _, n := range root.list("leads", action) {
+			n := n
+			t := WebhookSetting(fmt.Sprintf("%s_lead", action))
+
+			if action == "note" {
+				add(t, func(e *WebhookEvent) { e.Note = webhookNote(n.get("note"), NoteEntityTypeLead) })
+				continue
+			}
+
+			add(t, func(e *WebhookEvent) {
+				e.Lead = webhookLead(n)
+				e.OldStatusId = n.num("old_status_id")
+				e.OldPipelineId = n.num("old_pipeline_id")
+			})
+		}
+	}
+
+	for _, entity := range []string{"contacts", "companies"} {
+		for _, action := range root.get(entity).keys() {
+			for _, n := range root.list(entity, action) {
+				n := n
+
+				isCompany := entity == "companies" || n.str("type") == "company"
+				if action == "note" {
+					noteEntity := NoteEntityTypeContact
+					t := WebhookNoteContact
+					if isCompany {
+						noteEntity, t = NoteEntityTypeCompany, WebhookNoteCompany
+					}
+					add(t, func(e *WebhookEvent) { e.Note = webhookNote(n.get("note"), noteEntity) })
+					continue
+				}
+
+				if isCompany {
+					add(WebhookSetting(fmt.Sprintf("%s_company", action)), func(e *WebhookEvent) { e.Company = webhookCompany(n) })
+				} else {
+					add(WebhookSetting(fmt.Sprintf("%s_contact", action)), func(e *WebhookEvent) { e.Contact = webhookContact(n) })
+				}
+			}
+		}
+	}
+
+	for _, action := range root.get("task").keys() {
+		for _, n := range root.list("task", action) {
+			n := n
+			add(WebhookSetting(fmt.Sprintf("%s_task", action)), func(e *WebhookEvent) { e.Task = webhookTask(n) })
+		}
+	}
+
+	return events
+}
+
+func webhookLead(n *formNode) *lead {
+	l := &lead{
+		Id:                 n.num("id"),
+		Name:               n.str("name"),
+		Price:              n.num("price"),
+		ResponsibleUserId:  n.num("responsible_user_id"),
+		StatusId:           n.num("status_id"),
+		PipelineId:         n.num("pipeline_id"),
+		CreatedBy:          n.num("created_user_id"),
+		UpdatedBy:          n.num("modified_user_id"),
+		CreatedAt:          webhookTime(n, "created_at", "date_create"),
+		UpdatedAt:          webhookTime(n, "updated_at", "last_modified"),
+		AccountId:          n.num("account_id"),
+		CustomFieldsValues: n.customFields(),
+	}
+	l.Embedded.Tags = n.tags()
+
+	return l
+}
+
+func webhookContact(n *formNode) *contact {
+	c := &contact{
+		Id:                 n.num("id"),
+		Name:               n.str("name"),
+		FirstName:          n.str("first_name"),
+		LastName:           n.str("last_name"),
+		ResponsibleUserId:  n.num("responsible_user_id"),
+		CreatedBy:          n.num("created_user_id"),
+		UpdatedBy:          n.num("modified_user_id"),
+		CreatedAt:          webhookTime(n, "created_at", "date_create"),
+		UpdatedAt:          webhookTime(n, "updated_at", "last_modified"),
+		AccountId:          n.num("account_id"),
+		CustomFieldsValues: n.customFields(),
+	}
+	c.Embedded.Tags = n.tags()
+
+	return c
+}
+
+func webhookCompany(n *formNode) *company {
+	c := &company{
+		Id:                 n.num("id"),
+		Name:               n.str("name"),
+		ResponsibleUserId:  n.num("responsible_user_id"),
+		CreatedBy:          n.num("created_user_id"),
+		UpdatedBy:          n.num("modified_user_id"),
+		CreatedAt:          webhookTime(n, "created_at", "date_create"),
+		UpdatedAt:          webhookTime(n, "updated_at", "last_modified"),
+		AccountId:          n.num("account_id"),
+		CustomFieldsValues: n.customFields(),
+	}
+	c.Embedded.Tags = n.tags()
+
+	return c
+}
+
+func webhookTask(n *formNode) *task {
+	t := &task{
+		Id:                n.num("id"),
+		ResponsibleUserId: n.num("responsible_user_id"),
+		CreatedBy:         n.num("created_user_id"),
+		UpdatedBy:         n.num("modified_user_id"),
+		CreatedAt:         webhookTime(n, "created_at", "date_create"),
+		UpdatedAt:         webhookTime(n, "updated_at", "last_modified"),
+		EntityId:          n.num("element_id"),
+		EntityType:        TaskEntityType(webhookEntityType(n.num("element_type"))),
+		IsCompleted:       n.flag("status"),
+		TaskTypeId:        TaskTypeIdType(n.num("task_type")),
+		Text:              n.str("text"),
+		CompleteTill:      n.num("complete_till"),
+		AccountID:         n.num("account_id"),
+	}
+	t.Result.Text = n.str("result", "text")
+
+	return t
+}
+
+func webhookNote(n *formNode, entity NoteEntityType) *note {
+	nt := &note{
+		Id:                n.num("id"),
+		EntityId:          n.num("element_id"),
+		CreatedBy:         n.num("created_by"),
+		ResponsibleUserId: n.num("main_user_id"),
+		CreatedAt:         webhookTime(n, "created_at", "date_create"),
+		UpdatedAt:         webhookTime(n, "updated_at", "last_modified"),
+		NoteType:          webhookNoteType(n.str("note_type")),
+		AccountId:         n.num("account_id"),
+		EntityType:        entity,
+	}
+	nt.Params.Text = n.str("text")
+
+	return nt
+}
+
+// webhookTime Возвращает первую заполненную дату из перечисленных полей
+func webhookTime(n *formNode, keys ...string) int {
+	for _, k := range keys {
+		if v := n.num(k); v != 0 {
+			return v
+		}
+	}
+
+	return 0
+}
+
+// webhookEntityType Переводит числовой element_type вебхука в тип сущности API v4
+func webhookEntityType(elementType int) string {
+	switch elementType {
+	case 1:
+		return "contacts"
+	case 2:
+		return "leads"
+	case 3:
+		return "companies"
+	case 12:
+		return "customers"
+	}
+
+	return ""
+}
+
+// webhookNoteType Переводит числовой note_type вебхука в тип примечания API v4
+func webhookNoteType(noteType string) NoteType {
+	switch noteType {
+	case "4":
+		return CommonNote
+	case "10":
+		return CallInNote
+	case "11":
+		return CallOutNote
+	case "25":
+		return ServiceMessageNote
+	case "102":
+		return SmsInNote
+	case "103":
+		return SmsOutNote
+	}
+
+	return NoteType(noteType)
+}
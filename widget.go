@@ -0,0 +1,170 @@
+package amocrm_v4
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// widgetMaxBody Максимальный размер тела запроса цифровой воронки
+const widgetMaxBody = 1 << 20
+
+// WidgetClaims Данные JWT, которым amoCRM подписывает запросы виджетов и цифровой воронки
+type WidgetClaims struct {
+	AccountId  int    `json:"account_id"`
+	UserId     int    `json:"user_id"`
+	ClientUuid string `json:"client_uuid"`
+	Issuer     string `json:"iss"` // Адрес аккаунта, например https://example.amocrm.ru
+	Id         string `json:"jti"`
+
+	Subdomain string    `json:"-"` // Поддомен аккаунта из Issuer
+	IssuedAt  time.Time `json:"-"`
+	ExpiresAt time.Time `json:"-"`
+}
+
+// WidgetAuthConfig Параметры проверки запросов виджета
+type WidgetAuthConfig struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// DigitalPipelineRequest Запрос виджета цифровой воронки или Salesbot
+type DigitalPipelineRequest struct {
+	Token     string          `json:"token"`
+	Data      json.RawMessage `json:"data"`
+	ReturnURL string          `json:"return_url"` // Адрес, на который отправляется результат для продолжения бота
+
+	Claims *WidgetClaims `json:"-"`
+}
+
+type widgetClaimsKey struct{}
+
+// VerifyWidgetToken Проверяет подпись и срок действия JWT виджета и возвращает его данные.
+// Токен должен быть выдан интеграции clientID и содержать ID аккаунта и срок действия.
+func VerifyWidgetToken(clientID, clientSecret, token string) (*WidgetClaims, error) {
+	raw := struct {
+		WidgetClaims
+		jwtRegisteredClaims
+	}{}
+	if err := verifyJWT(token, []byte(clientSecret), &raw); err != nil {
+		return nil, err
+	}
+
+	claims := raw.WidgetClaims
+	if claims.ClientUuid != "" && claims.ClientUuid != clientID {
+		return nil, fmt.Errorf("%w: токен выдан для интеграции %s", ErrInvalidToken, claims.ClientUuid)
+	}
+	if claims.AccountId == 0 {
+		return nil, fmt.Errorf("%w: не указан account_id", ErrInvalidToken)
+	}
+
+	if claims.Issuer != "" {
+		iss, err := url.Parse(claims.Issuer)
+		if err != nil {
+			return nil, fmt.Errorf("%w: некорректный iss %q", ErrInvalidToken, claims.Issuer)
+		}
+		m := oauthReferer.FindStringSubmatch(iss.Host)
+		if m == nil {
+			return nil, fmt.Errorf("%w: некорректный iss %q", ErrInvalidToken, claims.Issuer)
+		}
+		claims.Subdomain = m[1]
+	}
+
+	claims.ExpiresAt = time.Unix(raw.Exp, 0)
+	if raw.Iat != 0 {
+		claims.IssuedAt = time.Unix(raw.Iat, 0)
+	}
+
+	return &claims, nil
+}
+
+// WidgetAuthMiddleware Пропускает к next только запросы с действительным JWT виджета в заголовке
+// Authorization: Bearer. Данные токена доступны обработчику через WidgetClaimsFromContext.
+func WidgetAuthMiddleware(cfg WidgetAuthConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := bearerToken(r)
+		if token == "" {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := VerifyWidgetToken(cfg.ClientID, cfg.ClientSecret, token)
+		if err != nil {
+			log.Warnf("Отклонен запрос виджета: %v", err)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), widgetClaimsKey{}, claims)))
+	})
+}
+
+// WidgetClaimsFromContext Возвращает данные JWT, проверенного WidgetAuthMiddleware
+func WidgetClaimsFromContext(ctx context.Context) (*WidgetClaims, bool) {
+	claims, ok := ctx.Value(widgetClaimsKey{}).(*WidgetClaims)
+
+	return claims, ok
+}
+
+// VerifyDigitalPipelineRequest Разбирает запрос цифровой воронки (JSON или форма) и проверяет его JWT.
+// Токен берется из заголовка Authorization: Bearer или из поля token. Тело запроса остается доступным для чтения.
+func VerifyDigitalPipelineRequest(clientID, clientSecret string, r *http.Request) (*DigitalPipelineRequest, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, widgetMaxBody+1))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения запроса: %v", err)
+	}
+	if len(body) > widgetMaxBody {
+		return nil, errors.New("превышен размер запроса")
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	req := &DigitalPipelineRequest{}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.Unmarshal(body, req); err != nil {
+			return nil, fmt.Errorf("ошибка разбора запроса: %v", err)
+		}
+	} else {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("ошибка разбора запроса: %v", err)
+		}
+		req.Token = form.Get("token")
+		req.ReturnURL = form.Get("return_url")
+		if data := form.Get("data"); data != "" {
+			req.Data = json.RawMessage(data)
+		}
+	}
+
+	if token := bearerToken(r); token != "" {
+		req.Token = token
+	}
+	if req.Token == "" {
+		return nil, fmt.Errorf("%w: запрос не подписан", ErrInvalidToken)
+	}
+
+	req.Claims, err = VerifyWidgetToken(clientID, clientSecret, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// bearerToken Возвращает токен из заголовка Authorization: Bearer
+func bearerToken(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return ""
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+}
@@ -0,0 +1,131 @@
+package amocrm_v4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testWidgetClientID = "f90ba33d-c9d9-44da-b76c-c349b0ecbe41"
+	testWidgetSecret   = "widget-secret"
+)
+
+// signTestJWT Подписывает claims алгоритмом HS256
+func signTestJWT(t *testing.T, secret string, claims map[string]interface{}) string {
+	t.Helper()
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func testWidgetClaims(change func(c map[string]interface{})) map[string]interface{} {
+	c := map[string]interface{}{
+		"account_id":  31,
+		"user_id":     7,
+		"client_uuid": testWidgetClientID,
+		"iss":         "https://example.amocrm.ru",
+		"iat":         time.Now().Unix(),
+		"exp":         time.Now().Add(time.Hour).Unix(),
+	}
+	if change != nil {
+		change(c)
+	}
+
+	return c
+}
+
+func TestVerifyWidgetToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		claims  map[string]interface{}
+		wantErr bool
+	}{
+		{"valid", testWidgetSecret, testWidgetClaims(nil), false},
+		{"bad signature", "other-secret", testWidgetClaims(nil), true},
+		{"expired", testWidgetSecret, testWidgetClaims(func(c map[string]interface{}) {
+			c["exp"] = time.Now().Add(-time.Hour).Unix()
+		}), true},
+		{"no exp", testWidgetSecret, testWidgetClaims(func(c map[string]interface{}) {
+			delete(c, "exp")
+		}), true},
+		{"wrong client", testWidgetSecret, testWidgetClaims(func(c map[string]interface{}) {
+			c["client_uuid"] = "another-integration"
+		}), true},
+		{"wrong iss", testWidgetSecret, testWidgetClaims(func(c map[string]interface{}) {
+			c["iss"] = "https://example.com"
+		}), true},
+		{"no account", testWidgetSecret, testWidgetClaims(func(c map[string]interface{}) {
+			delete(c, "account_id")
+		}), true},
+	}
+
+	for _, tt := range tests {
+		claims, err := VerifyWidgetToken(testWidgetClientID, testWidgetSecret, signTestJWT(t, tt.secret, tt.claims))
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("%s: err = %v, want ErrInvalidToken", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+
+		if claims.AccountId != 31 || claims.UserId != 7 || claims.Subdomain != "example" {
+			t.Errorf("%s: unexpected claims %+v", tt.name, claims)
+		}
+		if claims.ExpiresAt.IsZero() || claims.IssuedAt.IsZero() {
+			t.Errorf("%s: times are not set: %+v", tt.name, claims)
+		}
+	}
+}
+
+func TestVerifyJWT(t *testing.T) {
+	valid := signTestJWT(t, testWidgetSecret, testWidgetClaims(nil))
+	parts := strings.Split(valid, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"two parts", parts[0] + "." + parts[1]},
+		{"alg none", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."},
+		{"tampered payload", parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"account_id":1}`)) + "." + parts[2]},
+		{"not yet valid", signTestJWT(t, testWidgetSecret, testWidgetClaims(func(c map[string]interface{}) {
+			c["nbf"] = time.Now().Add(time.Hour).Unix()
+		}))},
+	}
+
+	for _, tt := range tests {
+		var claims WidgetClaims
+		if err := verifyJWT(tt.token, []byte(testWidgetSecret), &claims); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: err = %v, want ErrInvalidToken", tt.name, err)
+		}
+	}
+
+	var claims WidgetClaims
+	if err := verifyJWT(valid, []byte(testWidgetSecret), &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.AccountId != 31 {
+		t.Errorf("AccountId = %d, want 31", claims.AccountId)
+	}
+}